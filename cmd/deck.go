@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+)
+
+// deckCmd represents the deck command
+var deckCmd = &cobra.Command{
+	Use:   "deck",
+	Short: "Manages Trees Against Humanity card decks",
+}
+
+// deckImportCmd represents the deck import command
+var deckImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Converts decks from JSON Against Humanity, CSV or TSV",
+	Long: `Converts decks into the server's own deck format.
+
+JSON Against Humanity exports may hold several packs, each of which
+becomes a deck. CSV and TSV files hold a single deck, with columns
+for the card type, text, pick count, tags and draw count. An
+optional row of type "deck" gives the deck's name and tags, and
+otherwise the deck is named after the file.
+
+The format is guessed from the file extension unless --format is
+given. Malformed cards are reported by line and index. The import
+fails if there are any, unless --skip-invalid is given.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format == "" {
+			format = formatFromExt(args[0], "jah")
+		}
+
+		decks, err := loadDecks(cmd, args[0], format)
+		if _, ok := err.(*game.ImportError); ok {
+			fmt.Fprintln(os.Stderr, err)
+			skip, _ := cmd.Flags().GetBool("skip-invalid")
+			if !skip {
+				return fmt.Errorf("%s contains malformed cards", args[0])
+			}
+		} else if err != nil {
+			return err
+		}
+
+		for _, deck := range decks {
+			fmt.Fprintf(os.Stderr, "%s: %d questions, %d answers\n", deck.Name, len(deck.QuestionCards), len(deck.AnswerCards))
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		return writeDecks(output, decks)
+	},
+}
+
+// deckLintCmd represents the deck lint command
+var deckLintCmd = &cobra.Command{
+	Use:   "lint <file>",
+	Short: "Checks decks for problems before they are uploaded",
+	Long: `Checks decks for problems before they are uploaded.
+
+Cards which are empty, overlong, draw too many or whose pick count
+doesn't match their blanks are errors. Repeated cards and decks with too few
+answers per question are warnings. Exits with a non-zero status
+if there are any errors.
+
+Accepts the same formats as import, as well as the server's own
+deck format, which is assumed for .json files.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format == "" {
+			format = formatFromExt(args[0], "deck")
+		}
+
+		decks, err := loadDecks(cmd, args[0], format)
+		if _, ok := err.(*game.ImportError); ok {
+			fmt.Println(err)
+		} else if err != nil {
+			return err
+		}
+
+		failed := err != nil
+		for i := range decks {
+			report := game.ValidateDeck(&decks[i])
+			for _, issue := range report.Issues {
+				fmt.Printf("%s: %s\n", decks[i].Name, issue)
+			}
+			fmt.Printf("%s: %d questions, %d answers, %.1f answers per question\n",
+				decks[i].Name, report.NumQuestions, report.NumAnswers, report.AnswerRatio)
+			failed = failed || report.HasErrors()
+		}
+
+		if failed {
+			return fmt.Errorf("%s has errors", args[0])
+		}
+		return nil
+	},
+}
+
+// deckExportCmd represents the deck export command
+var deckExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Converts a deck to CSV or TSV",
+	Long: `Converts a deck from the server's own deck format to CSV or TSV,
+for editing in a spreadsheet. The deck's name and tags are kept in
+a row of type "deck". Deck and card IDs are not kept, and are
+renumbered on import.
+
+If the file holds several decks, the one to export must be chosen
+with --deck.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		decks, err := readDecks(args[0])
+		if err != nil {
+			return err
+		}
+
+		deckID, _ := cmd.Flags().GetInt("deck")
+		var deck *game.Deck
+		for i := range decks {
+			if decks[i].ID == deckID || (deckID == 0 && len(decks) == 1) {
+				deck = &decks[i]
+			}
+		}
+		if deck == nil {
+			return fmt.Errorf("%s holds %d decks, choose one with --deck", args[0], len(decks))
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		if format == "" {
+			format = formatFromExt(output, "")
+		}
+		if format != "csv" && format != "tsv" {
+			return fmt.Errorf("unknown export format %q", format)
+		}
+
+		opts, err := csvOptions(cmd, format)
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+
+		return game.WriteCSV(out, *deck, opts)
+	},
+}
+
+// loadDecks reads decks from a file in the given format.
+//
+// Malformed cards are reported in an `*game.ImportError`,
+// alongside the decks built from the rest.
+func loadDecks(cmd *cobra.Command, name, format string) (decks []game.Deck, err error) {
+	if format == "deck" {
+		return readDecks(name)
+	}
+
+	in, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	switch format {
+	case "jah":
+		return game.ImportJSONAgainstHumanity(in)
+	case "csv", "tsv":
+		opts, err := csvOptions(cmd, format)
+		if err != nil {
+			return nil, err
+		}
+
+		deck, err := game.ReadCSV(in, opts)
+		deck.ID = 1
+		deck.NumberCards()
+		if deck.Name == "" {
+			deck.Name = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+		}
+		return []game.Deck{deck}, err
+	}
+	return nil, fmt.Errorf("unknown deck format %q", format)
+}
+
+// readDecks reads decks in the server's own format, accepting
+// either a single deck or a list of them.
+func readDecks(name string) (decks []game.Deck, err error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &decks)
+	if err == nil {
+		return decks, nil
+	}
+
+	deck := game.Deck{}
+	if json.Unmarshal(data, &deck) != nil {
+		return nil, err
+	}
+	return []game.Deck{deck}, nil
+}
+
+// writeDecks writes decks as JSON to the named file, or to
+// standard output if no name is given.
+func writeDecks(name string, decks []game.Deck) (err error) {
+	var out io.Writer = os.Stdout
+	if name != "" {
+		f, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(decks)
+}
+
+// formatFromExt guesses a deck format from a file extension,
+// using `jsonFormat` for JSON files.
+func formatFromExt(name, jsonFormat string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return "csv"
+	case ".tsv", ".tab":
+		return "tsv"
+	case ".json":
+		return jsonFormat
+	}
+	return "csv"
+}
+
+// csvOptions builds CSV options from the command's flags.
+func csvOptions(cmd *cobra.Command, format string) (opts game.CSVOptions, err error) {
+	if format == "tsv" {
+		opts.Comma = '\t'
+	}
+
+	header, _ := cmd.Flags().GetString("header")
+	switch header {
+	case "auto":
+		opts.Header = game.HeaderAuto
+	case "yes":
+		opts.Header = game.HeaderPresent
+	case "no":
+		opts.Header = game.HeaderAbsent
+	default:
+		return opts, fmt.Errorf("--header must be auto, yes or no, got %q", header)
+	}
+	return opts, nil
+}
+
+func init() {
+	rootCmd.AddCommand(deckCmd)
+	deckCmd.AddCommand(deckImportCmd)
+	deckCmd.AddCommand(deckExportCmd)
+	deckCmd.AddCommand(deckLintCmd)
+
+	deckImportCmd.Flags().StringP("format", "f", "", "Input format: jah, csv or tsv")
+	deckImportCmd.Flags().StringP("output", "o", "", "File to write decks to, instead of standard output")
+	deckImportCmd.Flags().Bool("skip-invalid", false, "Import the remaining cards when some are malformed")
+	deckImportCmd.Flags().String("header", "auto", "Whether CSV input has a header row: auto, yes or no")
+
+	deckLintCmd.Flags().StringP("format", "f", "", "Input format: deck, jah, csv or tsv")
+	deckLintCmd.Flags().String("header", "auto", "Whether CSV input has a header row: auto, yes or no")
+
+	deckExportCmd.Flags().StringP("format", "f", "", "Output format: csv or tsv")
+	deckExportCmd.Flags().StringP("output", "o", "", "File to write the deck to, instead of standard output")
+	deckExportCmd.Flags().Int("deck", 0, "ID of the deck to export")
+	deckExportCmd.Flags().String("header", "auto", "Whether to write a header row: auto, yes or no")
+}
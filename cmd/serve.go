@@ -44,7 +44,7 @@ func init() {
 	rootCmd.AddCommand(serveCmd)
 
 	serveCmd.Flags().IntP("port", "p", 8000, "Port of the TAH server")
-	serveCmd.Flags().StringArray("allowed-origins", []string{"*"}, "Allowed origins according to CORS standard")
+	serveCmd.Flags().StringArray("allowed-origins", []string{"*"}, "Allowed origins according to CORS standard. Websockets are also accepted from these origins, other than *, and always from the server's own origin")
 	serveCmd.Flags().StringP("secret", "s", "secret-key", "Key used for encrypting session data")
 
 	viper.BindPFlag("port", serveCmd.Flags().Lookup("port"))
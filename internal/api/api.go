@@ -3,17 +3,21 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"unicode/utf8"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
 	"github.com/rjacobs31/trees-against-humanity-server/internal/middleware"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/storage"
 )
 
 // Setup adds all API routes to given router.
-func Setup(router *mux.Router, store sessions.Store) {
+func Setup(router *mux.Router, store sessions.Store, games GameRegistry, decks *storage.DeckRepository) {
 	mustAuth := middleware.MustAuth(store)
-	rm := RoomManager{store: store}
+	rm := RoomManager{games: games, store: store}
+	dm := DeckManager{decks: decks, store: store}
 
 	router.Handle("/test", http.HandlerFunc(handleTest))
 
@@ -23,6 +27,34 @@ func Setup(router *mux.Router, store sessions.Store) {
 
 	router.HandleFunc("/games", rm.HandleGetRooms).Methods("GET")
 	router.HandleFunc("/games", mustAuth(rm.HandleCreateRoom)).Methods("POST")
+	router.HandleFunc("/games/{id:[0-9]+}/settings", mustAuth(rm.HandlePatchSettings)).Methods("PATCH")
+
+	router.HandleFunc("/decks", dm.HandleListDecks).Methods("GET")
+	router.HandleFunc("/decks", mustAuth(dm.HandleCreateDeck)).Methods("POST")
+	router.HandleFunc("/decks/{id:[0-9]+}", dm.HandleGetDeck).Methods("GET")
+	router.HandleFunc("/decks/{id:[0-9]+}", mustAuth(dm.HandleUpdateDeck)).Methods("PUT")
+	router.HandleFunc("/decks/{id:[0-9]+}", mustAuth(dm.HandleDeleteDeck)).Methods("DELETE")
+	router.HandleFunc("/decks/{id:[0-9]+}/clone", mustAuth(dm.HandleCloneDeck)).Methods("POST")
+	router.HandleFunc("/decks/{id:[0-9]+}/questions", mustAuth(dm.HandleAddQuestion)).Methods("POST")
+	router.HandleFunc("/decks/{id:[0-9]+}/questions/{cardID:[0-9]+}", mustAuth(dm.HandleRemoveQuestion)).Methods("DELETE")
+	router.HandleFunc("/decks/{id:[0-9]+}/answers", mustAuth(dm.HandleAddAnswer)).Methods("POST")
+	router.HandleFunc("/decks/{id:[0-9]+}/answers/{cardID:[0-9]+}", mustAuth(dm.HandleRemoveAnswer)).Methods("DELETE")
+}
+
+// MinUsernameLength is the fewest characters a username may
+// have.
+const MinUsernameLength int = 4
+
+// ValidateUsername checks that a username may be logged in
+// with.
+//
+// Every way of logging in, and of connecting once logged in,
+// applies the same check.
+func ValidateUsername(name string) error {
+	if utf8.RuneCountInString(name) < MinUsernameLength {
+		return fmt.Errorf("username must be at least %d characters", MinUsernameLength)
+	}
+	return nil
 }
 
 type sessionHandler struct {
@@ -54,8 +86,8 @@ func (s *sessionHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Username == "" {
-		http.Error(w, "Username must be non-empty", http.StatusBadRequest)
+	if err = ValidateUsername(req.Username); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -0,0 +1,27 @@
+package api
+
+import "testing"
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		valid    bool
+	}{
+		{"empty", "", false},
+		{"too short", "bob", false},
+		{"shortest", "bobb", true},
+		{"long", "roberta", true},
+		{"counts characters not bytes", "zoë", false},
+		{"multibyte", "zoëy", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUsername(tt.username)
+			if (err == nil) != tt.valid {
+				t.Errorf("ValidateUsername(%q) = %v, want valid %v", tt.username, err, tt.valid)
+			}
+		})
+	}
+}
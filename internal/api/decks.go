@@ -0,0 +1,330 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/storage"
+)
+
+// DeckManager serves the deck library.
+//
+// Decks may be read by anyone, but only changed by the user
+// who created them.
+type DeckManager struct {
+	decks *storage.DeckRepository
+	store sessions.Store
+}
+
+// DeckInfo summarises a deck for display in a deck list.
+type DeckInfo struct {
+	ID           int      `json:"id"`
+	Name         string   `json:"name"`
+	Owner        string   `json:"owner"`
+	NumQuestions int      `json:"numQuestions"`
+	NumAnswers   int      `json:"numAnswers"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+type cloneDeckRequest struct {
+	Name string `json:"name"`
+}
+
+func (dm *DeckManager) HandleListDecks(w http.ResponseWriter, r *http.Request) {
+	decks, err := dm.decks.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	infos := make([]DeckInfo, 0, len(decks))
+	for _, deck := range decks {
+		infos = append(infos, DeckInfo{
+			ID:           deck.ID,
+			Name:         deck.Name,
+			Owner:        deck.Owner,
+			NumQuestions: len(deck.QuestionCards),
+			NumAnswers:   len(deck.AnswerCards),
+			Tags:         deck.Tags,
+		})
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+func (dm *DeckManager) HandleGetDeck(w http.ResponseWriter, r *http.Request) {
+	deck, ok := dm.deck(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, deck)
+}
+
+func (dm *DeckManager) HandleCreateDeck(w http.ResponseWriter, r *http.Request) {
+	deck := game.Deck{}
+	if !readJSON(w, r, &deck) {
+		return
+	}
+
+	if deck.Name == "" {
+		http.Error(w, "Deck name must be non-empty", http.StatusBadRequest)
+		return
+	}
+
+	if !checkDeck(w, &deck) {
+		return
+	}
+
+	deck.Owner = dm.username(r)
+	err := dm.decks.Create(&deck)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, deck)
+}
+
+func (dm *DeckManager) HandleUpdateDeck(w http.ResponseWriter, r *http.Request) {
+	existing, ok := dm.ownDeck(w, r)
+	if !ok {
+		return
+	}
+
+	deck := game.Deck{}
+	if !readJSON(w, r, &deck) {
+		return
+	}
+
+	if deck.Name == "" {
+		http.Error(w, "Deck name must be non-empty", http.StatusBadRequest)
+		return
+	}
+
+	if !checkDeck(w, &deck) {
+		return
+	}
+
+	deck.ID = existing.ID
+	deck.Owner = existing.Owner
+	dm.save(w, &deck, http.StatusOK)
+}
+
+func (dm *DeckManager) HandleDeleteDeck(w http.ResponseWriter, r *http.Request) {
+	deck, ok := dm.ownDeck(w, r)
+	if !ok {
+		return
+	}
+
+	err := dm.decks.Delete(deck.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (dm *DeckManager) HandleCloneDeck(w http.ResponseWriter, r *http.Request) {
+	deck, ok := dm.deck(w, r)
+	if !ok {
+		return
+	}
+
+	req := cloneDeckRequest{}
+	if r.ContentLength > 0 && !readJSON(w, r, &req) {
+		return
+	}
+
+	if req.Name == "" {
+		req.Name = "Copy of " + deck.Name
+	}
+
+	deck.Name = req.Name
+	deck.Owner = dm.username(r)
+	err := dm.decks.Create(deck)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, deck)
+}
+
+func (dm *DeckManager) HandleAddQuestion(w http.ResponseWriter, r *http.Request) {
+	deck, ok := dm.ownDeck(w, r)
+	if !ok {
+		return
+	}
+
+	card := game.QuestionCard{}
+	if !readJSON(w, r, &card) {
+		return
+	}
+
+	card.ID = 0
+	deck.QuestionCards = append(deck.QuestionCards, card)
+	if !checkDeck(w, deck) {
+		return
+	}
+	dm.save(w, deck, http.StatusCreated)
+}
+
+func (dm *DeckManager) HandleRemoveQuestion(w http.ResponseWriter, r *http.Request) {
+	deck, ok := dm.ownDeck(w, r)
+	if !ok {
+		return
+	}
+
+	cardID, _ := strconv.Atoi(mux.Vars(r)["cardID"])
+	for i, card := range deck.QuestionCards {
+		if card.ID == cardID {
+			deck.QuestionCards = append(deck.QuestionCards[:i], deck.QuestionCards[i+1:]...)
+			dm.save(w, deck, http.StatusOK)
+			return
+		}
+	}
+	http.Error(w, "Card not found", http.StatusNotFound)
+}
+
+func (dm *DeckManager) HandleAddAnswer(w http.ResponseWriter, r *http.Request) {
+	deck, ok := dm.ownDeck(w, r)
+	if !ok {
+		return
+	}
+
+	card := game.AnswerCard{}
+	if !readJSON(w, r, &card) {
+		return
+	}
+
+	card.ID = 0
+	deck.AnswerCards = append(deck.AnswerCards, card)
+	if !checkDeck(w, deck) {
+		return
+	}
+	dm.save(w, deck, http.StatusCreated)
+}
+
+func (dm *DeckManager) HandleRemoveAnswer(w http.ResponseWriter, r *http.Request) {
+	deck, ok := dm.ownDeck(w, r)
+	if !ok {
+		return
+	}
+
+	cardID, _ := strconv.Atoi(mux.Vars(r)["cardID"])
+	for i, card := range deck.AnswerCards {
+		if card.ID == cardID {
+			deck.AnswerCards = append(deck.AnswerCards[:i], deck.AnswerCards[i+1:]...)
+			dm.save(w, deck, http.StatusOK)
+			return
+		}
+	}
+	http.Error(w, "Card not found", http.StatusNotFound)
+}
+
+// deck retrieves the deck named in the request path,
+// replying with an error if there is none.
+func (dm *DeckManager) deck(w http.ResponseWriter, r *http.Request) (deck *game.Deck, ok bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid deck ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	deck, err = dm.decks.Get(id)
+	if err == storage.ErrDeckNotFound {
+		http.Error(w, "Deck not found", http.StatusNotFound)
+		return nil, false
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return deck, true
+}
+
+// ownDeck retrieves the deck named in the request path,
+// replying with an error unless it belongs to the user.
+func (dm *DeckManager) ownDeck(w http.ResponseWriter, r *http.Request) (deck *game.Deck, ok bool) {
+	deck, ok = dm.deck(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	if deck.Owner != dm.username(r) {
+		http.Error(w, "Only the deck's owner may change it", http.StatusForbidden)
+		return nil, false
+	}
+	return deck, true
+}
+
+// checkDeck validates a deck before it is stored, replying
+// with the problems found if any of its cards is in error.
+//
+// Problems with the deck as a whole, such as having no
+// answer cards yet, are allowed while it is being built.
+func checkDeck(w http.ResponseWriter, deck *game.Deck) bool {
+	report := game.ValidateDeck(deck)
+
+	problems := []string{}
+	for _, issue := range report.Issues {
+		if issue.Severity == game.Error && issue.Kind != "deck" {
+			problems = append(problems, issue.String())
+		}
+	}
+	if len(problems) > 0 {
+		http.Error(w, strings.Join(problems, "\n"), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// save stores a changed deck and replies with it.
+func (dm *DeckManager) save(w http.ResponseWriter, deck *game.Deck, status int) {
+	err := dm.decks.Update(deck)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, status, deck)
+}
+
+// username retrieves the name of the logged in user.
+func (dm *DeckManager) username(r *http.Request) string {
+	session, _ := dm.store.Get(r, "session-name")
+	name, _ := session.Values["username"].(string)
+	return name
+}
+
+// readJSON decodes a JSON request body, replying with an
+// error if it is malformed.
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		http.Error(w, "Could not deserialise", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeJSON replies with a value serialised as JSON.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
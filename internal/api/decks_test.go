@@ -0,0 +1,197 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/storage"
+)
+
+// deckServer serves the deck routes from a fresh library.
+type deckServer struct {
+	t      *testing.T
+	router *mux.Router
+	store  sessions.Store
+}
+
+func newDeckServer(t *testing.T) *deckServer {
+	t.Helper()
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	decks, err := storage.NewDeckRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &deckServer{t: t, router: mux.NewRouter(), store: sessions.NewCookieStore([]byte("test-key"))}
+	Setup(s.router, s.store, nil, decks)
+	return s
+}
+
+// do sends a request as the named user, returning the reply.
+func (s *deckServer) do(method, path, username, body string) *httptest.ResponseRecorder {
+	s.t.Helper()
+
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if username != "" {
+		login := httptest.NewRecorder()
+		session, _ := s.store.New(r, "session-name")
+		session.Values["username"] = username
+		if err := session.Save(r, login); err != nil {
+			s.t.Fatal(err)
+		}
+		for _, cookie := range login.Result().Cookies() {
+			r.AddCookie(cookie)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, r)
+	return w
+}
+
+// deck decodes a deck from a reply, failing unless it has
+// the wanted status.
+func (s *deckServer) deck(w *httptest.ResponseRecorder, status int) game.Deck {
+	s.t.Helper()
+
+	if w.Code != status {
+		s.t.Fatalf("got status %d, want %d: %s", w.Code, status, w.Body.String())
+	}
+	deck := game.Deck{}
+	if err := json.Unmarshal(w.Body.Bytes(), &deck); err != nil {
+		s.t.Fatal(err)
+	}
+	return deck
+}
+
+func TestCreateDeckRenumbersDuplicateIDs(t *testing.T) {
+	s := newDeckServer(t)
+
+	deck := s.deck(s.do("POST", "/decks", "alice", `{
+		"name": "Base",
+		"questionCards": [{"id": 1, "text": "Why? ____"}],
+		"answerCards": [{"id": 1, "text": "Bees?"}, {"id": 1, "text": "Oprah."}, {"text": "A windmill."}]
+	}`), http.StatusCreated)
+
+	seen := map[int]bool{}
+	for _, card := range deck.AnswerCards {
+		if seen[card.ID] {
+			t.Errorf("answer ID %d is used twice", card.ID)
+		}
+		seen[card.ID] = true
+	}
+	if deck.Owner != "alice" {
+		t.Errorf("owner is %q, want alice", deck.Owner)
+	}
+	if deck.QuestionCards[0].NumAnswers != 1 {
+		t.Errorf("question picks %d, want it inferred as 1", deck.QuestionCards[0].NumAnswers)
+	}
+}
+
+func TestRemovedCardIDsAreNotReused(t *testing.T) {
+	s := newDeckServer(t)
+
+	deck := s.deck(s.do("POST", "/decks", "alice", `{
+		"name": "Base",
+		"answerCards": [{"text": "Bees?"}, {"text": "Oprah."}]
+	}`), http.StatusCreated)
+
+	s.deck(s.do("DELETE", "/decks/1/answers/2", "alice", ""), http.StatusOK)
+	deck = s.deck(s.do("POST", "/decks/1/answers", "alice", `{"text": "Vigorous jazz hands."}`), http.StatusCreated)
+
+	if got := deck.AnswerCards[len(deck.AnswerCards)-1].ID; got != 3 {
+		t.Errorf("new answer has ID %d, want 3", got)
+	}
+}
+
+func TestDeckUploadsAreValidated(t *testing.T) {
+	long := strings.Repeat("a", game.MaxCardTextLength+1)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "create with empty answer",
+			method:  "POST",
+			path:    "/decks",
+			body:    `{"name": "New", "answerCards": [{"text": "Bees?"}, {"text": " "}]}`,
+			wantErr: "error: answer 1: text is empty",
+		},
+		{
+			name:    "create with mismatched pick",
+			method:  "POST",
+			path:    "/decks",
+			body:    `{"name": "New", "questionCards": [{"text": "____ and ____", "numAnswers": 1}]}`,
+			wantErr: "error: question 0: has 2 blanks but picks 1",
+		},
+		{
+			name:    "update with too many draws",
+			method:  "PUT",
+			path:    "/decks/1",
+			body:    `{"name": "Base", "questionCards": [{"text": "Why? ____", "draw": 4}]}`,
+			wantErr: "error: question 0: draw must be between 0 and 3, got 4",
+		},
+		{
+			name:    "add overlong answer",
+			method:  "POST",
+			path:    "/decks/1/answers",
+			body:    `{"text": "` + long + `"}`,
+			wantErr: "error: answer 1: text is 301 characters, at most 300 allowed",
+		},
+		{
+			name:    "add question picking too many",
+			method:  "POST",
+			path:    "/decks/1/questions",
+			body:    `{"text": "Why?", "numAnswers": 4}`,
+			wantErr: "error: question 0: pick must be between 1 and 3, got 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newDeckServer(t)
+			s.deck(s.do("POST", "/decks", "alice", `{"name": "Base", "answerCards": [{"text": "Bees?"}]}`), http.StatusCreated)
+
+			w := s.do(tt.method, tt.path, "alice", tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("got status %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantErr {
+				t.Errorf("got error %q, want %q", got, tt.wantErr)
+			}
+
+			deck := s.deck(s.do("GET", "/decks/1", "", ""), http.StatusOK)
+			if len(deck.AnswerCards) != 1 || len(deck.QuestionCards) != 0 {
+				t.Errorf("rejected upload changed the stored deck to %+v", deck)
+			}
+		})
+	}
+}
+
+func TestDeckWithoutCardsMayBeCreated(t *testing.T) {
+	s := newDeckServer(t)
+
+	deck := s.deck(s.do("POST", "/decks", "alice", `{"name": "Empty"}`), http.StatusCreated)
+	if deck.ID != 1 {
+		t.Errorf("deck has ID %d, want 1", deck.ID)
+	}
+}
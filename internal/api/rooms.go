@@ -5,47 +5,52 @@ import (
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
+	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+)
+
+// GameRegistry is the source of the games served by
+// the API.
+type GameRegistry interface {
+	// ListGames summarises all active games.
+	ListGames() []RoomInfo
+
+	// CreateGame creates a game owned by the named user,
+	// playing with the decks with the given IDs.
+	CreateGame(username, name, password string, deckIDs []int) (*RoomInfo, error)
+
+	// PatchSettings applies a partial JSON update to the
+	// settings of a game owned by the named user.
+	PatchSettings(username string, gameID int, patch json.RawMessage) (*game.GameSettings, error)
+}
+
+var (
+	// ErrGameNotFound is returned when a game doesn't exist.
+	ErrGameNotFound = errors.New("game not found")
+
+	// ErrNotGameOwner is returned when a user tries to change
+	// a game they don't own.
+	ErrNotGameOwner = errors.New("only the game's owner may change it")
 )
 
 type RoomManager struct {
-	roomCounter int
-	rooms       map[int]room
-	store       sessions.Store
+	games GameRegistry
+	store sessions.Store
 }
 
 func (rm *RoomManager) GetRooms() (infos []RoomInfo) {
-	infos = make([]RoomInfo, 0, len(rm.rooms))
-	for _, v := range rm.rooms {
-		infos = append(infos, *v.toRoomInfo())
-	}
-	return
+	return rm.games.ListGames()
 }
 
-func (rm *RoomManager) CreateRoom(name, password string) (info *RoomInfo, err error) {
+func (rm *RoomManager) CreateRoom(username, name, password string, deckIDs []int) (info *RoomInfo, err error) {
 	if len(name) < 4 {
 		return nil, errors.New("room name too short")
 	}
 
-	if rm.rooms == nil {
-		rm.rooms = make(map[int]room)
-	}
-
-	for _, v := range rm.rooms {
-		if v.name == name {
-			return nil, errors.New("room name exists")
-		}
-	}
-
-	rm.roomCounter++
-	rm.rooms[rm.roomCounter] = room{
-		id:       rm.roomCounter,
-		name:     name,
-		password: password,
-	}
-
-	return rm.rooms[rm.roomCounter].toRoomInfo(), nil
+	return rm.games.CreateGame(username, name, password, deckIDs)
 }
 
 func (rm *RoomManager) HandleGetRooms(w http.ResponseWriter, r *http.Request) {
@@ -74,7 +79,10 @@ func (rm *RoomManager) HandleCreateRoom(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	info, err := rm.CreateRoom(req.Name, req.Password)
+	session, _ := rm.store.Get(r, "session-name")
+	username, _ := session.Values["username"].(string)
+
+	info, err := rm.CreateRoom(username, req.Name, req.Password, req.DeckIDs)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -84,14 +92,33 @@ func (rm *RoomManager) HandleCreateRoom(w http.ResponseWriter, r *http.Request)
 	w.Write(res)
 }
 
-type room struct {
-	id       int
-	name     string
-	password string
-}
+func (rm *RoomManager) HandlePatchSettings(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
 
-func (r room) toRoomInfo() *RoomInfo {
-	return &RoomInfo{ID: r.id, Name: r.name}
+	patch, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	session, _ := rm.store.Get(r, "session-name")
+	username, _ := session.Values["username"].(string)
+
+	settings, err := rm.games.PatchSettings(username, id, patch)
+	switch {
+	case err == ErrGameNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err == ErrNotGameOwner:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeJSON(w, http.StatusOK, settings)
+	}
 }
 
 type RoomInfo struct {
@@ -102,4 +129,5 @@ type RoomInfo struct {
 type createRoomRequest struct {
 	Name     string `json:"name"`
 	Password string `json:"password"`
+	DeckIDs  []int  `json:"deckIds"`
 }
@@ -1,11 +1,16 @@
 package internal
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
 	"time"
 
+	"github.com/gorilla/sessions"
 	"github.com/gorilla/websocket"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/api"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/messages"
 )
 
 const (
@@ -29,6 +34,7 @@ type Client struct {
 	hub        *Hub
 	connection *websocket.Conn
 	send       chan []byte
+	username   string
 }
 
 // ReadPump begins accepting messages from the client.
@@ -47,7 +53,25 @@ func (c *Client) ReadPump() {
 			break
 		}
 
-		c.send <- message
+		c.hub.incoming <- clientMessage{client: c, data: message}
+	}
+}
+
+// Send queues a message to be written to the client.
+//
+// The message is dropped if the client's send buffer
+// is full.
+func (c *Client) Send(msg messages.OutgoingMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	select {
+	case c.send <- data:
+	default:
+		log.Println("client send buffer full, dropping message")
 	}
 }
 
@@ -65,6 +89,7 @@ func (c *Client) WritePump() {
 			if !ok {
 				// The Hub closed the channel.
 				c.connection.WriteMessage(websocket.CloseMessage, []byte{})
+				return
 			}
 
 			w, err := c.connection.NextWriter(websocket.TextMessage)
@@ -87,7 +112,22 @@ func (c *Client) WritePump() {
 
 // ServeWs establishes a websocket connection and begins
 // handling messages for it.
-func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
+//
+// The connection is refused unless the request belongs
+// to a logged in session.
+func ServeWs(hub *Hub, upgrader *websocket.Upgrader, store sessions.Store, w http.ResponseWriter, r *http.Request) {
+	session, _ := store.Get(r, "session-name")
+	name, ok := session.Values["username"].(string)
+	if !ok || name == "" {
+		http.Error(w, "Must be logged in", http.StatusUnauthorized)
+		return
+	}
+
+	if err := api.ValidateUsername(name); err != nil {
+		http.Error(w, "Must log in again: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
@@ -97,6 +137,7 @@ func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
 		hub:        hub,
 		connection: conn,
 		send:       make(chan []byte, 8192),
+		username:   name,
 	}
 	client.hub.register <- client
 
@@ -0,0 +1,364 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/messages"
+)
+
+// clientMessage is a raw message read from a client,
+// awaiting dispatch by the Hub.
+type clientMessage struct {
+	client *Client
+	data   []byte
+}
+
+// dispatch decodes a message from a client and routes it
+// to the appropriate handler.
+func (h *Hub) dispatch(c *Client, data []byte) {
+	msg := messages.IncomingMessage{}
+	err := json.Unmarshal(data, &msg)
+	if err != nil {
+		c.Send(messages.NewError(messages.MalformedMessage, "could not decode message"))
+		return
+	}
+
+	if msg.Type == messages.Connect {
+		h.handleConnect(c)
+		return
+	}
+
+	userID := h.clients[c]
+	if userID == 0 {
+		c.Send(messages.NewError(messages.NotConnected, "must connect before sending messages"))
+		return
+	}
+
+	switch msg.Type {
+	case messages.Disconnect:
+		h.handleDisconnect(c)
+	case messages.CreateGame:
+		h.handleCreateGame(c, userID, msg.Data)
+	case messages.JoinGame:
+		h.handleJoinGame(c, userID, msg.Data)
+	case messages.LeaveGame:
+		h.handleLeaveGame(c, userID, msg.Data)
+	case messages.StartGame:
+		h.handleStartGame(c, userID, msg.Data)
+	case messages.SubmitCards:
+		h.handleSubmitCards(c, userID, msg.Data)
+	case messages.SelectWinner:
+		h.handleSelectWinner(c, userID, msg.Data)
+	case messages.NextRound:
+		h.handleNextRound(c, userID, msg.Data)
+	case messages.RebootUniverse:
+		h.handleReplaceHand(c, userID, msg.Data, true)
+	case messages.Mulligan:
+		h.handleReplaceHand(c, userID, msg.Data, false)
+	case messages.CastVote:
+		h.handleCastVote(c, userID, msg.Data)
+	case messages.UpdateSettings:
+		h.handleUpdateSettings(c, userID, msg.Data)
+	default:
+		c.Send(messages.NewError(messages.UnknownMessage, "unknown message type"))
+	}
+}
+
+func (h *Hub) handleConnect(c *Client) {
+	id := h.clients[c]
+	if id == 0 {
+		var err error
+		id, err = h.ConnectUser(c)
+		if err != nil {
+			c.Send(messages.NewError(messages.RequestFailed, err.Error()))
+			return
+		}
+	}
+
+	c.Send(messages.OutgoingMessage{
+		Type: messages.Connected,
+		Data: messages.ConnectedData{UserID: id, Username: c.username},
+	})
+	c.Send(messages.OutgoingMessage{
+		Type: messages.FullGamesList,
+		Data: h.gamesList(),
+	})
+}
+
+func (h *Hub) handleDisconnect(c *Client) {
+	h.disconnect(c)
+	c.Send(messages.OutgoingMessage{Type: messages.Disconnected})
+}
+
+func (h *Hub) handleCreateGame(c *Client, userID int, data json.RawMessage) {
+	req := messages.CreateGameData{}
+	if !decodeData(c, data, &req) {
+		return
+	}
+
+	id, err := h.AddGame(userID, req.Name, req.Password, req.DeckIDs)
+	if err != nil {
+		c.Send(messages.NewError(messages.RequestFailed, err.Error()))
+		return
+	}
+
+	c.Send(messages.OutgoingMessage{
+		Type: messages.GameCreated,
+		Data: h.gameInfo(id),
+	})
+	h.broadcastGame(id)
+}
+
+func (h *Hub) handleJoinGame(c *Client, userID int, data json.RawMessage) {
+	req := messages.JoinGameData{}
+	if !decodeData(c, data, &req) {
+		return
+	}
+
+	err := h.JoinGame(userID, req.GameID, req.Password)
+	if err != nil {
+		c.Send(messages.NewError(messages.RequestFailed, err.Error()))
+		return
+	}
+
+	c.Send(messages.OutgoingMessage{
+		Type: messages.GameJoined,
+		Data: h.gameInfo(req.GameID),
+	})
+}
+
+func (h *Hub) handleLeaveGame(c *Client, userID int, data json.RawMessage) {
+	req := messages.LeaveGameData{}
+	if !decodeData(c, data, &req) {
+		return
+	}
+
+	err := h.LeaveGame(userID, req.GameID)
+	if err != nil {
+		c.Send(messages.NewError(messages.RequestFailed, err.Error()))
+		return
+	}
+
+	c.Send(messages.OutgoingMessage{
+		Type: messages.GameLeft,
+		Data: messages.LeaveGameData{GameID: req.GameID},
+	})
+}
+
+func (h *Hub) handleStartGame(c *Client, userID int, data json.RawMessage) {
+	req := messages.GameActionData{}
+	if !decodeData(c, data, &req) {
+		return
+	}
+
+	h.actOnGame(c, userID, req.GameID, func(g *game.Game) error {
+		if g.Owner == nil || g.Owner.ID != userID {
+			return errors.New("only the owner may start the game")
+		}
+		return g.Start()
+	})
+}
+
+func (h *Hub) handleSubmitCards(c *Client, userID int, data json.RawMessage) {
+	req := messages.SubmitCardsData{}
+	if !decodeData(c, data, &req) {
+		return
+	}
+
+	h.actOnGame(c, userID, req.GameID, func(g *game.Game) error {
+		return g.SubmitCards(userID, req.Cards)
+	})
+}
+
+func (h *Hub) handleSelectWinner(c *Client, userID int, data json.RawMessage) {
+	req := messages.SelectWinnerData{}
+	if !decodeData(c, data, &req) {
+		return
+	}
+
+	ok := h.actOnGame(c, userID, req.GameID, func(g *game.Game) error {
+		return g.SelectWinner(userID, req.SubmissionID)
+	})
+	if ok {
+		h.broadcastResult(req.GameID)
+	}
+}
+
+func (h *Hub) handleNextRound(c *Client, userID int, data json.RawMessage) {
+	req := messages.GameActionData{}
+	if !decodeData(c, data, &req) {
+		return
+	}
+
+	h.actOnGame(c, userID, req.GameID, func(g *game.Game) error {
+		return g.NextRound()
+	})
+}
+
+func (h *Hub) handleCastVote(c *Client, userID int, data json.RawMessage) {
+	req := messages.CastVoteData{}
+	if !decodeData(c, data, &req) {
+		return
+	}
+
+	ok := h.actOnGame(c, userID, req.GameID, func(g *game.Game) error {
+		return g.CastVote(userID, req.SubmissionID)
+	})
+	if ok && h.Games[req.GameID].Round.Winner != nil {
+		h.broadcastResult(req.GameID)
+	}
+}
+
+func (h *Hub) handleUpdateSettings(c *Client, userID int, data json.RawMessage) {
+	req := messages.UpdateSettingsData{}
+	if !decodeData(c, data, &req) {
+		return
+	}
+
+	if h.Games[req.GameID] == nil || h.Games[req.GameID].Player(userID) == nil {
+		c.Send(messages.NewError(messages.RequestFailed, "player not in game"))
+		return
+	}
+
+	_, err := h.UpdateSettings(userID, req.GameID, req.Settings)
+	if err != nil {
+		c.Send(messages.NewError(messages.RequestFailed, err.Error()))
+	}
+}
+
+// handleReplaceHand trades in a player's hand, either by
+// rebooting the universe or by taking a mulligan.
+func (h *Hub) handleReplaceHand(c *Client, userID int, data json.RawMessage, reboot bool) {
+	req := messages.GameActionData{}
+	if !decodeData(c, data, &req) {
+		return
+	}
+
+	ok := h.actOnGame(c, userID, req.GameID, func(g *game.Game) error {
+		if reboot {
+			return g.RebootUniverse(userID)
+		}
+		return g.Mulligan(userID)
+	})
+	if !ok {
+		return
+	}
+
+	h.broadcast(req.GameID, messages.OutgoingMessage{
+		Type: messages.HandReplaced,
+		Data: messages.HandReplacedData{
+			GameID:   req.GameID,
+			PlayerID: userID,
+			Username: h.Users[userID].Username,
+			Reboot:   reboot,
+		},
+	})
+}
+
+// actOnGame applies an action to a game the user has joined,
+// broadcasting the new state on success and replying with an
+// error otherwise.
+//
+// Reports whether the action succeeded.
+func (h *Hub) actOnGame(c *Client, userID, gameID int, action func(*game.Game) error) bool {
+	g, ok := h.Games[gameID]
+	if !ok {
+		c.Send(messages.NewError(messages.RequestFailed, "invalid game ID"))
+		return false
+	}
+
+	if g.Player(userID) == nil {
+		c.Send(messages.NewError(messages.RequestFailed, "player not in game"))
+		return false
+	}
+
+	err := action(g)
+	if err != nil {
+		c.Send(messages.NewError(messages.RequestFailed, err.Error()))
+		return false
+	}
+
+	h.updateGame(gameID)
+	return true
+}
+
+// detach unties a client from its user when the connection
+// closes, leaving the user in their games so they may
+// reconnect later.
+func (h *Hub) detach(c *Client) {
+	userID := h.clients[c]
+	user, ok := h.Users[userID]
+	if !ok || user.Client != c {
+		return
+	}
+
+	user.Client = nil
+	h.Users[userID] = user
+}
+
+// disconnect removes the user connected through a client
+// from all of their games and from the Hub.
+func (h *Hub) disconnect(c *Client) {
+	userID := h.clients[c]
+	if userID == 0 {
+		return
+	}
+
+	for id, g := range h.Games {
+		if g.Player(userID) == nil {
+			continue
+		}
+		if err := h.LeaveGame(userID, id); err != nil {
+			log.Println(err)
+		}
+	}
+
+	if err := h.RemoveUser(userID); err != nil {
+		log.Println(err)
+	}
+}
+
+// gamesList summarises all active games.
+func (h *Hub) gamesList() (infos []messages.GameInfo) {
+	infos = make([]messages.GameInfo, 0, len(h.Games))
+	for id, g := range h.Games {
+		if g.Settings.Visibility == game.Unlisted {
+			continue
+		}
+		infos = append(infos, h.gameInfo(id))
+	}
+	return
+}
+
+// gameInfo summarises a single game.
+func (h *Hub) gameInfo(id int) (info messages.GameInfo) {
+	g, ok := h.Games[id]
+	if !ok {
+		return
+	}
+
+	info = messages.GameInfo{
+		ID:          g.ID,
+		Name:        g.Name,
+		NumPlayers:  g.NumHumans(),
+		HasPassword: g.Password != "",
+	}
+	if g.Owner != nil {
+		info.Owner = g.Owner.Username
+	}
+	return
+}
+
+// decodeData decodes a message payload, replying with an
+// error if it is malformed.
+func decodeData(c *Client, data json.RawMessage, v interface{}) bool {
+	err := json.Unmarshal(data, v)
+	if err != nil {
+		c.Send(messages.NewError(messages.MalformedMessage, "could not decode message data"))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/messages"
+)
+
+// connectTestClient connects a new test client as the named
+// user, discarding the replies.
+func connectTestClient(t *testing.T, h *Hub, username string) *Client {
+	t.Helper()
+
+	c := newTestClient(h)
+	c.username = username
+	h.dispatch(c, []byte(`{"type":0}`))
+	received(t, c)
+	if h.clients[c] == 0 {
+		t.Fatalf("%s could not connect", username)
+	}
+	return c
+}
+
+// errorCode decodes the code of a single `Error` reply.
+func errorCode(t *testing.T, msgs []receivedMessage) messages.ErrorCode {
+	t.Helper()
+
+	if len(msgs) != 1 || msgs[0].Type != messages.Error {
+		t.Fatalf("got replies %+v, want a single error", msgs)
+	}
+	data := messages.ErrorData{}
+	if err := json.Unmarshal(msgs[0].Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	return data.Code
+}
+
+func TestDispatchConnect(t *testing.T) {
+	h, _ := newTestHub(t)
+	c := newTestClient(h)
+	c.username = "alice"
+
+	h.dispatch(c, []byte(`{"type":0}`))
+	msgs := received(t, c)
+	if len(msgs) != 2 || msgs[0].Type != messages.Connected || msgs[1].Type != messages.FullGamesList {
+		t.Fatalf("got replies %+v, want Connected then FullGamesList", msgs)
+	}
+
+	data := messages.ConnectedData{}
+	if err := json.Unmarshal(msgs[0].Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if want := (messages.ConnectedData{UserID: 3, Username: "alice"}); data != want {
+		t.Errorf("connected as %+v, want %+v", data, want)
+	}
+	if h.clients[c] != 3 || h.Users[3].Client != c {
+		t.Error("client not tied to its user")
+	}
+
+	games := []messages.GameInfo{}
+	if err := json.Unmarshal(msgs[1].Data, &games); err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 || games[0].ID != 1 {
+		t.Errorf("listed games %+v, want game 1", games)
+	}
+}
+
+func TestDispatchErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		connected bool
+		message   string
+		want      messages.ErrorCode
+	}{
+		{"malformed message", false, `{"type":`, messages.MalformedMessage},
+		{"not connected", false, `{"type":3,"data":{"gameId":1}}`, messages.NotConnected},
+		{"unknown type", true, `{"type":99}`, messages.UnknownMessage},
+		{"malformed data", true, `{"type":3,"data":{"gameId":"one"}}`, messages.MalformedMessage},
+		{"invalid game", true, `{"type":3,"data":{"gameId":9}}`, messages.RequestFailed},
+		{"not in game", true, `{"type":5,"data":{"gameId":1}}`, messages.RequestFailed},
+		{"create without deck library", true, `{"type":2,"data":{"name":"New","deckIds":[1]}}`, messages.RequestFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := newTestHub(t)
+			c := newTestClient(h)
+			if tt.connected {
+				c = connectTestClient(t, h, "alice")
+			}
+
+			h.dispatch(c, []byte(tt.message))
+			if code := errorCode(t, received(t, c)); code != tt.want {
+				t.Errorf("got error code %q, want %q", code, tt.want)
+			}
+			if len(h.Games) != 1 || h.Games[1].Player(3) != nil {
+				t.Error("rejected message changed the games")
+			}
+		})
+	}
+}
+
+func TestDispatchJoinGame(t *testing.T) {
+	h, g := newTestHub(t)
+	c := connectTestClient(t, h, "alice")
+
+	h.dispatch(c, []byte(`{"type":3,"data":{"gameId":1}}`))
+	want := []messages.OutgoingMessageType{messages.GameState, messages.GameJoined}
+	if got := receivedTypes(t, c); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got replies %v, want %v", got, want)
+	}
+	if g.Player(3) == nil {
+		t.Error("player not added to game")
+	}
+}
+
+func TestDispatchCreateGame(t *testing.T) {
+	h, _ := newTestHub(t)
+	c := connectTestClient(t, h, "alice")
+
+	h.dispatch(c, []byte(`{"type":2,"data":{"name":"New game"}}`))
+	msgs := received(t, c)
+	if len(msgs) != 2 || msgs[0].Type != messages.GameCreated || msgs[1].Type != messages.GameState {
+		t.Fatalf("got replies %+v, want GameCreated then GameState", msgs)
+	}
+
+	info := messages.GameInfo{}
+	if err := json.Unmarshal(msgs[0].Data, &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.ID != 2 || info.Name != "New game" || info.Owner != "alice" {
+		t.Errorf("created game %+v", info)
+	}
+}
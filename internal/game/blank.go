@@ -0,0 +1,64 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxBlankCards is the most blank cards which may be
+	// added to a game's answer pool.
+	MaxBlankCards int = 100
+
+	// MaxBlankTextLength is the longest text, in characters,
+	// which may be written on a blank card.
+	MaxBlankTextLength int = 100
+)
+
+// AddBlanks puts the given number of blank cards into the
+// answer deck and shuffles it.
+//
+// Blank cards belong to no deck, and are numbered from 1.
+func (p *PlayDeck) AddBlanks(n int) {
+	if n < 1 {
+		return
+	}
+
+	for i := 1; i <= n; i++ {
+		p.AnswerDeck.Deck = append(p.AnswerDeck.Deck, AnswerCard{
+			ID:    i,
+			Blank: true,
+		})
+	}
+	p.AnswerDeck.Shuffle(p.rand())
+}
+
+// SanitiseBlankText cleans up text written on a blank card.
+//
+// Control characters are dropped and runs of whitespace are
+// collapsed into single spaces. Fails if nothing is left or
+// the text is longer than `MaxBlankTextLength`.
+func SanitiseBlankText(text string) (string, error) {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, text)
+	text = strings.Join(strings.Fields(text), " ")
+
+	if text == "" {
+		return "", errors.New("blank card must have text written on it")
+	}
+
+	if utf8.RuneCountInString(text) > MaxBlankTextLength {
+		return "", fmt.Errorf("blank card text must be at most %d characters", MaxBlankTextLength)
+	}
+	return text, nil
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// submitBlank has a player write on a blank card given to
+// them, and submit it.
+func submitBlank(t *testing.T, g *Game, player *Player, id int, text string) {
+	t.Helper()
+
+	player.Hand[0] = &AnswerCard{ID: id, Blank: true}
+	card := AnswerCard{ID: id, Blank: true, Text: text}
+	if err := g.SubmitCards(player.ID, []AnswerCard{card}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWrittenBlanksRecorded(t *testing.T) {
+	g := newTestGame(t, 4, 1)
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	winner := g.seatAfter(g.Round.Czar)
+	loser := g.seatAfter(winner)
+	submitBlank(t, g, winner, 1, "  my   words ")
+	submitBlank(t, g, loser, 2, "Something else.")
+	submitAll(t, g)
+	czarPick(t, g)
+
+	result := g.LastResult()
+	want := []WrittenBlank{
+		{Player: winner.ID, Username: winner.Username, Text: "my words"},
+		{Player: loser.ID, Username: loser.Username, Text: "Something else."},
+	}
+	if !reflect.DeepEqual(result.Blanks, want) {
+		t.Errorf("recorded blanks %+v, want %+v", result.Blanks, want)
+	}
+	if result.Cards[0].Text != "my words" {
+		t.Errorf("winning card reads %q, want %q", result.Cards[0].Text, "my words")
+	}
+
+	if err := g.NextRound(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.History[0].Blanks, want) {
+		t.Errorf("blanks changed once the round was over: %+v", g.History[0].Blanks)
+	}
+
+	s, err := g.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := Snapshot{}
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := Restore(&loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.History[0].Blanks, want) {
+		t.Errorf("restored blanks %+v, want %+v", restored.History[0].Blanks, want)
+	}
+}
+
+func TestNoBlanksRecorded(t *testing.T) {
+	g := newTestGame(t, 3, 1)
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+	submitAll(t, g)
+	czarPick(t, g)
+
+	if blanks := g.LastResult().Blanks; blanks != nil {
+		t.Errorf("recorded blanks %+v in a round without any", blanks)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	_ "encoding/json"
 	"errors"
 	"math/rand"
-	"time"
 )
 
 // Deck represents a deck of answer cards and question cards
@@ -14,45 +13,131 @@ type Deck struct {
 	ID            int            `json:"id"`
 	AnswerCards   []AnswerCard   `json:"answerCards"`
 	Name          string         `json:"name"`
+	Owner         string         `json:"owner,omitempty"`
 	QuestionCards []QuestionCard `json:"questionCards"`
+
+	// Tags apply to every card in the deck.
+	Tags []string `json:"tags,omitempty"`
+
+	// LastQuestionID and LastAnswerID are the highest card IDs
+	// the deck has used, including those of removed cards.
+	LastQuestionID int `json:"lastQuestionId,omitempty"`
+	LastAnswerID   int `json:"lastAnswerId,omitempty"`
+}
+
+// NumberCards gives an ID to each card which lacks one, or
+// which repeats the ID of an earlier card, and marks every
+// card as belonging to the deck.
+//
+// New IDs follow the highest the deck has ever used, so a
+// removed card's ID is never given to another card.
+func (d *Deck) NumberCards() {
+	ids := make([]*int, len(d.QuestionCards))
+	for i := range d.QuestionCards {
+		ids[i] = &d.QuestionCards[i].ID
+		d.QuestionCards[i].DeckID = d.ID
+	}
+	d.LastQuestionID = numberIDs(ids, d.LastQuestionID)
+
+	ids = make([]*int, len(d.AnswerCards))
+	for i := range d.AnswerCards {
+		ids[i] = &d.AnswerCards[i].ID
+		d.AnswerCards[i].DeckID = d.ID
+	}
+	d.LastAnswerID = numberIDs(ids, d.LastAnswerID)
+}
+
+// numberIDs replaces missing and repeated IDs with new ones
+// following the highest ID used, returning the new highest.
+func numberIDs(ids []*int, last int) int {
+	for _, id := range ids {
+		if *id > last {
+			last = *id
+		}
+	}
+
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if *id < 1 || seen[*id] {
+			last++
+			*id = last
+		}
+		seen[*id] = true
+	}
+	return last
 }
 
 // QuestionCard represents a black question card.
+//
+// Players are dealt `Draw` extra cards when the question is
+// revealed, before they answer.
 type QuestionCard struct {
-	ID         int    `json:"id"`
-	NumAnswers int    `json:"numAnswers"`
-	Text       string `json:"text"`
+	ID         int      `json:"id"`
+	DeckID     int      `json:"deckId"`
+	NumAnswers int      `json:"numAnswers"`
+	Draw       int      `json:"draw,omitempty"`
+	Text       string   `json:"text"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// Pick returns the number of answer cards required to
+// answer the question.
+func (c QuestionCard) Pick() int {
+	if c.NumAnswers < 1 {
+		return 1
+	}
+	return c.NumAnswers
 }
 
 // AnswerCard represents a white answer card.
+//
+// Blank cards have their text written by the player who
+// plays them.
 type AnswerCard struct {
-	ID   int    `json:"id"`
-	Text string `json:"text"`
+	ID     int      `json:"id"`
+	DeckID int      `json:"deckId"`
+	Text   string   `json:"text"`
+	Tags   []string `json:"tags,omitempty"`
+	Blank  bool     `json:"blank,omitempty"`
+}
+
+// Is reports whether two answer cards are the same card.
+//
+// Card IDs are only unique within their source deck, and
+// blank cards are numbered separately.
+func (c AnswerCard) Is(other AnswerCard) bool {
+	return c.ID == other.ID && c.DeckID == other.DeckID && c.Blank == other.Blank
 }
 
 // PlayDeck contains both the answer deck and the question deck.
 type PlayDeck struct {
-	AnswerDeck   CardDeck
-	QuestionDeck CardDeck
+	AnswerDeck   CardDeck[AnswerCard]
+	QuestionDeck CardDeck[QuestionCard]
+
+	random *Random
 }
 
 // CardDeck represents a deck of cards, with a deck portion
 // to draw from and a discard pile to discard to.
-type CardDeck struct {
-	Deck        []interface{}
-	DiscardPile []interface{}
+//
+// Cards are drawn from the end of the deck portion.
+type CardDeck[T any] struct {
+	Deck        []T
+	DiscardPile []T
 }
 
-// Init initialises a card deck with the appropriate type.
-func (d *CardDeck) Init(cards []interface{}) (err error) {
-	d.Deck = cards
+// Init fills the deck with the given cards and empties the
+// discard pile.
+func (d *CardDeck[T]) Init(cards []T) (err error) {
+	d.Deck = append([]T(nil), cards...)
+	d.DiscardPile = nil
 	return
 }
 
 // Draw retrieves the top card in the deck and removes it.
-func (d *CardDeck) Draw() (card interface{}, err error) {
+func (d *CardDeck[T]) Draw() (card T, err error) {
 	if len(d.Deck) < 1 {
-		return nil, errors.New("card deck empty")
+		return card, errors.New("card deck empty")
 	}
 	deckLength := len(d.Deck) - 1
 	card = d.Deck[deckLength]
@@ -60,48 +145,120 @@ func (d *CardDeck) Draw() (card interface{}, err error) {
 	return
 }
 
+// DrawN retrieves the top `n` cards in the deck and removes
+// them, in the order they would have been drawn.
+//
+// Nothing is drawn if the deck holds fewer than `n` cards.
+func (d *CardDeck[T]) DrawN(n int) (cards []T, err error) {
+	if n < 0 {
+		return nil, errors.New("can't draw a negative number of cards")
+	}
+	if len(d.Deck) < n {
+		return nil, errors.New("not enough cards in deck")
+	}
+
+	cards = make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		card, _ := d.Draw()
+		cards = append(cards, card)
+	}
+	return
+}
+
+// Peek retrieves the top card in the deck without removing it.
+func (d *CardDeck[T]) Peek() (card T, err error) {
+	if len(d.Deck) < 1 {
+		return card, errors.New("card deck empty")
+	}
+	return d.Deck[len(d.Deck)-1], nil
+}
+
+// Remaining returns the number of cards left to draw before
+// the discard pile must be reshuffled.
+func (d *CardDeck[T]) Remaining() int {
+	return len(d.Deck)
+}
+
 // Discard adds a card to the discard pile.
-func (d *CardDeck) Discard(card interface{}) (err error) {
-	d.Deck = append(d.Deck, card)
+func (d *CardDeck[T]) Discard(card T) (err error) {
+	d.DiscardPile = append(d.DiscardPile, card)
 	return
 }
 
 // Shuffle randomises the order of the non-discard deck.
-func (d *CardDeck) Shuffle() {
-	r := rand.New(rand.NewSource(time.Now().Unix()))
-	for n := len(d.Deck); n > 0; n-- {
-		randIndex := r.Intn(n)
-		d.Deck[n-1], d.Deck[randIndex] = d.Deck[randIndex], d.Deck[n-1]
-	}
+func (d *CardDeck[T]) Shuffle(r *rand.Rand) {
+	shuffle(r, d.Deck)
 }
 
 // Reshuffle puts the discard pile back in the deck and shuffles.
-func (d *CardDeck) Reshuffle() {
-	d.Deck, d.DiscardPile = append(d.Deck, d.Discard), d.DiscardPile[:0]
-	r := rand.New(rand.NewSource(time.Now().Unix()))
-	for n := len(d.Deck); n > 0; n-- {
-		randIndex := r.Intn(n)
-		d.Deck[n-1], d.Deck[randIndex] = d.Deck[randIndex], d.Deck[n-1]
-	}
+func (d *CardDeck[T]) Reshuffle(r *rand.Rand) {
+	d.Deck, d.DiscardPile = append(d.Deck, d.DiscardPile...), nil
+	shuffle(r, d.Deck)
 }
 
 // Init sets up the PlayDeck by loading decks and emptying discard piles.
-func (p *PlayDeck) Init(deck *Deck) {
-	questionCards := make([]interface{}, 0, len(deck.QuestionCards))
-	for card := range deck.QuestionCards {
-		questionCards = append(questionCards, card)
-	}
+//
+// The cards of all decks are merged, as by `MergeDecks`,
+// leaving out cards rejected by the filter. Filtering is
+// done before shuffling.
+//
+// All shuffling is done with the given source of randomness.
+func (p *PlayDeck) Init(random *Random, filter TagFilter, decks ...*Deck) {
+	p.random = random
 
-	answerCards := make([]interface{}, 0, len(deck.AnswerCards))
-	for card := range deck.AnswerCards {
-		answerCards = append(answerCards, card)
-	}
+	questionCards, answerCards := MergeDecks(filter, decks...)
+	p.QuestionDeck.Init(questionCards)
+	p.AnswerDeck.Init(answerCards)
 
-	p.QuestionDeck.Init([]interface{}(questionCards))
-	p.AnswerDeck.Init([]interface{}(answerCards))
+	p.QuestionDeck.Shuffle(p.rand())
+	p.AnswerDeck.Shuffle(p.rand())
+}
+
+// MergeDecks collects the cards of several decks which pass
+// the given filter.
+//
+// Cards whose text repeats that of an earlier card are left
+// out. Each card keeps the ID of the deck it came from, and
+// questions lacking a number of answers have it counted from
+// their blanks. A deck's tags apply to each of its cards.
+func MergeDecks(filter TagFilter, decks ...*Deck) (questionCards []QuestionCard, answerCards []AnswerCard) {
+	questionCards = []QuestionCard{}
+	answerCards = []AnswerCard{}
+	seenQuestions := map[string]bool{}
+	seenAnswers := map[string]bool{}
+
+	for _, deck := range decks {
+		for _, card := range deck.QuestionCards {
+			if seenQuestions[card.Text] || !filter.Allows(deck.Tags, card.Tags) {
+				continue
+			}
+			seenQuestions[card.Text] = true
+			card.DeckID = deck.ID
+			if card.NumAnswers == 0 {
+				card.NumAnswers = CountBlanks(card.Text)
+			}
+			questionCards = append(questionCards, card)
+		}
 
-	p.QuestionDeck.Shuffle()
-	p.AnswerDeck.Shuffle()
+		for _, card := range deck.AnswerCards {
+			if seenAnswers[card.Text] || !filter.Allows(deck.Tags, card.Tags) {
+				continue
+			}
+			seenAnswers[card.Text] = true
+			card.DeckID = deck.ID
+			answerCards = append(answerCards, card)
+		}
+	}
+	return
+}
+
+// rand returns the PlayDeck's source of randomness,
+// seeding a new one if it has none.
+func (p *PlayDeck) rand() *rand.Rand {
+	if p.random == nil {
+		p.random = NewRandom(NewSeed())
+	}
+	return p.random.Rand
 }
 
 // DrawQuestion removes a question card from the deck and returns it.
@@ -111,28 +268,21 @@ func (p *PlayDeck) Init(deck *Deck) {
 //
 // If both piles are empty, an error is returned.
 func (p *PlayDeck) DrawQuestion() (card *QuestionCard, err error) {
-	if len(p.QuestionDeck.Deck) < 1 {
-		p.QuestionDeck.Reshuffle()
+	if p.QuestionDeck.Remaining() < 1 {
+		p.QuestionDeck.Reshuffle(p.rand())
 	}
 
 	drawnCard, err := p.QuestionDeck.Draw()
 	if err != nil {
 		return nil, err
 	}
-
-	switch v := drawnCard.(type) {
-	case *QuestionCard:
-		return v, nil
-	default:
-		return nil, errors.New("non-question card returned")
-	}
+	return &drawnCard, nil
 }
 
 // DiscardQuestion takes the given card and puts it into the discard
 // pile.
 func (p *PlayDeck) DiscardQuestion(card QuestionCard) (err error) {
-	p.QuestionDeck.Discard(card)
-	return nil
+	return p.QuestionDeck.Discard(card)
 }
 
 // DrawAnswer removes an answer card from the deck and returns it.
@@ -142,32 +292,30 @@ func (p *PlayDeck) DiscardQuestion(card QuestionCard) (err error) {
 //
 // If both piles are empty, an error is returned.
 func (p *PlayDeck) DrawAnswer() (card *AnswerCard, err error) {
-	if len(p.AnswerDeck.Deck) < 1 {
-		p.AnswerDeck.Reshuffle()
+	if p.AnswerDeck.Remaining() < 1 {
+		p.AnswerDeck.Reshuffle(p.rand())
 	}
 
 	drawnCard, err := p.AnswerDeck.Draw()
 	if err != nil {
 		return nil, err
 	}
-
-	switch v := drawnCard.(type) {
-	case *AnswerCard:
-		return v, nil
-	default:
-		return nil, errors.New("non-answer card returned")
-	}
+	return &drawnCard, nil
 }
 
 // DiscardAnswer takes the given card and puts it into the discard
 // pile.
+//
+// Text written on blank cards is wiped, so that they may be
+// used again.
 func (p *PlayDeck) DiscardAnswer(card AnswerCard) (err error) {
-	p.AnswerDeck.Discard(card)
-	return nil
+	if card.Blank {
+		card.Text = ""
+	}
+	return p.AnswerDeck.Discard(card)
 }
 
-func shuffle(vals []interface{}) {
-	r := rand.New(rand.NewSource(time.Now().Unix()))
+func shuffle[T any](r *rand.Rand, vals []T) {
 	for n := len(vals); n > 0; n-- {
 		randIndex := r.Intn(n)
 		vals[n-1], vals[randIndex] = vals[randIndex], vals[n-1]
@@ -0,0 +1,129 @@
+package game
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCardDeckDraw(t *testing.T) {
+	deck := CardDeck[int]{}
+	cards := []int{1, 2, 3}
+	deck.Init(cards)
+	cards[2] = 99
+
+	if card, err := deck.Peek(); err != nil || card != 3 {
+		t.Errorf("peeked at %d, %v, want 3", card, err)
+	}
+	if deck.Remaining() != 3 {
+		t.Errorf("peeking left %d cards, want 3", deck.Remaining())
+	}
+
+	for _, want := range []int{3, 2, 1} {
+		card, err := deck.Draw()
+		if err != nil || card != want {
+			t.Fatalf("drew %d, %v, want %d", card, err, want)
+		}
+	}
+
+	if _, err := deck.Draw(); err == nil {
+		t.Error("drew from an empty deck")
+	}
+	if _, err := deck.Peek(); err == nil {
+		t.Error("peeked at an empty deck")
+	}
+}
+
+func TestCardDeckDrawN(t *testing.T) {
+	deck := CardDeck[int]{}
+	deck.Init([]int{1, 2, 3, 4})
+
+	cards, err := deck.DrawN(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{4, 3, 2}; !reflect.DeepEqual(cards, want) {
+		t.Errorf("drew %v, want %v", cards, want)
+	}
+
+	if _, err := deck.DrawN(2); err == nil {
+		t.Error("drew more cards than the deck holds")
+	}
+	if _, err := deck.DrawN(-1); err == nil {
+		t.Error("drew a negative number of cards")
+	}
+	if deck.Remaining() != 1 {
+		t.Errorf("failed draws left %d cards, want 1", deck.Remaining())
+	}
+
+	if cards, err := deck.DrawN(0); err != nil || len(cards) != 0 {
+		t.Errorf("drawing no cards gave %v, %v", cards, err)
+	}
+}
+
+func TestCardDeckReshuffle(t *testing.T) {
+	deck := CardDeck[int]{}
+	deck.Init([]int{1, 2})
+	deck.Discard(3)
+	deck.Discard(4)
+
+	deck.Reshuffle(NewRandom(1).Rand)
+	if len(deck.DiscardPile) != 0 {
+		t.Errorf("discard pile still holds %v", deck.DiscardPile)
+	}
+
+	cards := append([]int(nil), deck.Deck...)
+	sort.Ints(cards)
+	if want := []int{1, 2, 3, 4}; !reflect.DeepEqual(cards, want) {
+		t.Errorf("reshuffled deck holds %v, want %v", cards, want)
+	}
+
+	deck.Init([]int{5})
+	if len(deck.Deck) != 1 || deck.DiscardPile != nil {
+		t.Errorf("Init left deck %v and discard pile %v", deck.Deck, deck.DiscardPile)
+	}
+}
+
+func TestPlayDeckReshufflesDiscards(t *testing.T) {
+	p := PlayDeck{}
+	p.Init(NewRandom(1), TagFilter{}, testDeck(1, 2))
+
+	// The first answer drawn is discarded as a written
+	// blank, which should come back wiped.
+	want := map[int]string{}
+	for i := 0; i < 2; i++ {
+		card, err := p.DrawAnswer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		card.Blank = i == 0
+		card.Text = "written"
+		want[card.ID] = card.Text
+		if card.Blank {
+			want[card.ID] = ""
+		}
+		p.DiscardAnswer(*card)
+	}
+
+	drawn := map[int]string{}
+	for i := 0; i < 2; i++ {
+		card, err := p.DrawAnswer()
+		if err != nil {
+			t.Fatalf("draw %d after emptying the deck: %v", i, err)
+		}
+		drawn[card.ID] = card.Text
+	}
+	if !reflect.DeepEqual(drawn, want) {
+		t.Errorf("drew %v after reshuffling, want %v", drawn, want)
+	}
+	if _, err := p.DrawAnswer(); err == nil {
+		t.Error("drew an answer with both piles empty")
+	}
+
+	if _, err := p.DrawQuestion(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.DrawQuestion(); err == nil {
+		t.Error("drew a question with both piles empty")
+	}
+}
@@ -0,0 +1,285 @@
+package game
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// HeaderMode determines whether the first row of a CSV deck
+// is treated as a header.
+type HeaderMode int
+
+const (
+	// HeaderAuto treats the first row as a header if it names
+	// the `type` and `text` columns.
+	HeaderAuto HeaderMode = iota
+
+	// HeaderPresent always treats the first row as a header.
+	HeaderPresent
+
+	// HeaderAbsent never treats the first row as a header.
+	HeaderAbsent
+)
+
+// csvColumns are the columns of a CSV deck, in the order used
+// when there is no header.
+var csvColumns = []string{"type", "text", "pick", "tags", "draw"}
+
+// CSVOptions controls how decks are read and written as
+// CSV or TSV.
+type CSVOptions struct {
+	// Comma is the field separator. Defaults to ',', while
+	// '\t' gives TSV.
+	Comma rune
+
+	// Header determines whether a header row is expected when
+	// reading. A header is written unless `HeaderAbsent`.
+	Header HeaderMode
+}
+
+func (o CSVOptions) comma() rune {
+	if o.Comma == 0 {
+		return ','
+	}
+	return o.Comma
+}
+
+// ReadCSV reads the cards of a deck from CSV or TSV.
+//
+// Each row holds one card, with columns for the card type
+// (`question` or `answer`), its text, the number of answers
+// a question asks for, a comma-separated list of tags and the
+// number of extra cards a question has players draw.
+// With a header row the columns may come in any order, and
+// only `type` and `text` are required.
+//
+// A row of type `deck` may give the deck's name in its text
+// column and the tags applying to the whole deck in its tags
+// column.
+//
+// Card IDs are numbered in order of appearance. The deck's ID
+// is left as 0, as are the cards' deck IDs, to be set with
+// `NumberCards` once the deck has one. Malformed rows
+// are skipped and reported together in an `*ImportError`,
+// alongside the deck built from the rest.
+func ReadCSV(r io.Reader, opts CSVOptions) (deck Deck, err error) {
+	cr := csv.NewReader(r)
+	cr.Comma = opts.comma()
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	deck.AnswerCards = []AnswerCard{}
+	deck.QuestionCards = []QuestionCard{}
+	columns := map[string]int{}
+	for i, name := range csvColumns {
+		columns[name] = i
+	}
+
+	entryErrors := []*EntryError{}
+	named := false
+	for row := 0; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Deck{}, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		if row == 0 && opts.Header != HeaderAbsent {
+			if opts.Header == HeaderPresent || isCSVHeader(record) {
+				columns, err = csvHeader(record)
+				if err != nil {
+					return Deck{}, fmt.Errorf("line %d: %v", line, err)
+				}
+				continue
+			}
+		}
+
+		if isDeckRow(record, columns) {
+			if named {
+				err = errors.New("deck row appears more than once")
+			} else {
+				named = true
+				err = deck.addCSVRecord(record, columns)
+			}
+		} else {
+			err = deck.addCSVRecord(record, columns)
+		}
+		if err != nil {
+			entryErrors = append(entryErrors, &EntryError{
+				Kind:  "row",
+				Index: row,
+				Line:  line,
+				Err:   err,
+			})
+		}
+	}
+
+	if len(entryErrors) > 0 {
+		return deck, &ImportError{Entries: entryErrors}
+	}
+	return deck, nil
+}
+
+// WriteCSV writes a deck as CSV or TSV, in the format read by
+// `ReadCSV`.
+//
+// The deck's name and tags are written first, in a `deck` row,
+// then question cards before answer cards. Deck and card IDs
+// aren't written, so are renumbered when read back.
+func WriteCSV(w io.Writer, deck Deck, opts CSVOptions) (err error) {
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.comma()
+
+	if opts.Header != HeaderAbsent {
+		err = cw.Write(csvColumns)
+		if err != nil {
+			return err
+		}
+	}
+
+	if deck.Name != "" || len(deck.Tags) > 0 {
+		err = cw.Write([]string{"deck", deck.Name, "", strings.Join(deck.Tags, ","), ""})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, card := range deck.QuestionCards {
+		pick := ""
+		if card.NumAnswers != 0 {
+			pick = strconv.Itoa(card.NumAnswers)
+		}
+		draw := ""
+		if card.Draw != 0 {
+			draw = strconv.Itoa(card.Draw)
+		}
+		err = cw.Write([]string{"question", card.Text, pick, strings.Join(card.Tags, ","), draw})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, card := range deck.AnswerCards {
+		err = cw.Write([]string{"answer", card.Text, "", strings.Join(card.Tags, ","), ""})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// addCSVRecord adds the card described by a CSV record, or
+// sets the deck's name and tags from a `deck` row.
+func (d *Deck) addCSVRecord(record []string, columns map[string]int) (err error) {
+	field := func(name string) string {
+		return csvField(record, columns, name)
+	}
+
+	text := field("text")
+	tags := parseTags(field("tags"))
+
+	if isDeckRow(record, columns) {
+		d.Name = strings.TrimSpace(text)
+		d.Tags = tags
+		return nil
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return errors.New("text is empty")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(field("type"))) {
+	case "question", "black", "q":
+		card := QuestionCard{
+			ID:     len(d.QuestionCards) + 1,
+			DeckID: d.ID,
+			Text:   text,
+			Tags:   tags,
+		}
+		if pick := strings.TrimSpace(field("pick")); pick != "" {
+			card.NumAnswers, err = strconv.Atoi(pick)
+			if err != nil || card.NumAnswers < 1 || card.NumAnswers > MaxNumAnswers {
+				return fmt.Errorf("pick must be between 1 and %d, got %q", MaxNumAnswers, pick)
+			}
+		}
+		if draw := strings.TrimSpace(field("draw")); draw != "" {
+			card.Draw, err = strconv.Atoi(draw)
+			if err != nil || card.Draw < 0 || card.Draw > MaxDraw {
+				return fmt.Errorf("draw must be between 0 and %d, got %q", MaxDraw, draw)
+			}
+		}
+		d.QuestionCards = append(d.QuestionCards, card)
+	case "answer", "white", "a":
+		d.AnswerCards = append(d.AnswerCards, AnswerCard{
+			ID:     len(d.AnswerCards) + 1,
+			DeckID: d.ID,
+			Text:   text,
+			Tags:   tags,
+		})
+	default:
+		return fmt.Errorf("unknown card type %q", field("type"))
+	}
+	return nil
+}
+
+// isDeckRow reports whether a record describes the deck
+// itself rather than a card.
+func isDeckRow(record []string, columns map[string]int) bool {
+	return strings.EqualFold(strings.TrimSpace(csvField(record, columns, "type")), "deck")
+}
+
+// csvField returns the named field of a record, or an empty
+// string if the record doesn't have it.
+func csvField(record []string, columns map[string]int, name string) string {
+	i, ok := columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// isCSVHeader reports whether a record looks like a header
+// row rather than a card.
+func isCSVHeader(record []string) bool {
+	names := map[string]bool{}
+	for _, name := range record {
+		names[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	return names["type"] && names["text"]
+}
+
+// csvHeader maps column names to their positions.
+func csvHeader(record []string) (columns map[string]int, err error) {
+	columns = map[string]int{}
+	for i, name := range record {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{"type", "text"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("header has no %q column", name)
+		}
+	}
+	return columns, nil
+}
+
+// parseTags splits a comma-separated list of tags, dropping
+// empty entries.
+func parseTags(list string) (tags []string) {
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	deck := Deck{
+		ID:   1,
+		Name: "Base Set",
+		Tags: []string{"base", "us"},
+
+		LastQuestionID: 2,
+		LastAnswerID:   2,
+		QuestionCards: []QuestionCard{
+			{ID: 1, DeckID: 1, Text: "Why can't I sleep at night?", NumAnswers: 1},
+			{ID: 2, DeckID: 1, Text: "____ + ____ = ____.", NumAnswers: 3, Draw: 2, Tags: []string{"maths"}},
+		},
+		AnswerCards: []AnswerCard{
+			{ID: 1, DeckID: 1, Text: "Bees?"},
+			{ID: 2, DeckID: 1, Text: "A windmill, full of \"corpses\".", Tags: []string{"nsfw", "dark"}},
+		},
+	}
+
+	for _, opts := range []CSVOptions{{}, {Comma: '\t'}, {Header: HeaderAbsent}} {
+		buf := &bytes.Buffer{}
+		if err := WriteCSV(buf, deck, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ReadCSV(buf, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.ID = deck.ID
+		got.NumberCards()
+
+		if !reflect.DeepEqual(got, deck) {
+			t.Errorf("options %+v: read back %+v, want %+v", opts, got, deck)
+		}
+	}
+}
+
+func TestReadCSVDeckRow(t *testing.T) {
+	in := "type,text,tags\ndeck,Extras,\"a, b\"\nanswer,Bees.,\ndeck,Again,\n"
+
+	deck, err := ReadCSV(bytes.NewBufferString(in), CSVOptions{})
+	importErr, ok := err.(*ImportError)
+	if !ok || len(importErr.Entries) != 1 || importErr.Entries[0].Line != 4 {
+		t.Fatalf("got error %v, want the second deck row reported", err)
+	}
+
+	if deck.Name != "Extras" || !reflect.DeepEqual(deck.Tags, []string{"a", "b"}) {
+		t.Errorf("got name %q and tags %q", deck.Name, deck.Tags)
+	}
+	if len(deck.AnswerCards) != 1 {
+		t.Errorf("got %d answers, want 1", len(deck.AnswerCards))
+	}
+}
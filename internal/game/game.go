@@ -1,10 +1,11 @@
 package game
 
 import (
-	// Imported for the JSON marshal/unmarshal interfaces.
-	_ "encoding/json"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"time"
 )
 
 // DefaultHandSize is the default number of cards
@@ -15,6 +16,10 @@ const DefaultHandSize int = 10
 // required for a player to win.
 const DefaultMaxPoints int = 5
 
+// MinPlayers is the minimum number of players required
+// to start a game.
+const MinPlayers int = 3
+
 // Phase represents which phase the game is currently in.
 type Phase int
 
@@ -33,35 +38,45 @@ const (
 
 	// EndOfGame is when the game is over and further action is required.
 	EndOfGame
+
+	// Voting is when players vote for a winning card, in
+	// place of `WinnerSelection` when there is no Czar.
+	Voting
 )
 
+var phaseNames = [...]string{"lobby", "roundInProgress", "winnerSelection", "endOfRound", "endOfGame", "voting"}
+
+// String returns the name of the phase.
+func (p Phase) String() string {
+	if int(p) < 0 || int(p) >= len(phaseNames) {
+		return "unknown"
+	}
+	return phaseNames[p]
+}
+
 // MarshalJSON attempts to serialise the phase as a JSON string.
 func (p Phase) MarshalJSON() (result []byte, err error) {
-	options := [...]string{"lobby", "roundInProgress", "winnerSelection", "endOfRound", "endOfGame"}
-	if int(p) < 0 || int(p) >= len(options) {
+	if int(p) < 0 || int(p) >= len(phaseNames) {
 		return nil, errors.New("invalid game phase")
 	}
-	result = []byte(options[p])
-	return
+	return json.Marshal(phaseNames[p])
 }
 
 // UnmarshalJSON attempts to deserialise phase from a JSON string.
 func (p *Phase) UnmarshalJSON(input []byte) (err error) {
-	switch string(input) {
-	case "lobby":
-		*p = Lobby
-	case "roundInProgress":
-		*p = RoundInProgress
-	case "winnerSelection":
-		*p = WinnerSelection
-	case "endOfRound":
-		*p = EndOfRound
-	case "endOfGame":
-		*p = EndOfGame
-	default:
-		err = errors.New("invalid Phase value")
+	var name string
+	err = json.Unmarshal(input, &name)
+	if err != nil {
+		return err
 	}
-	return
+
+	for i, option := range phaseNames {
+		if option == name {
+			*p = Phase(i)
+			return nil
+		}
+	}
+	return errors.New("invalid Phase value")
 }
 
 // Player represents a user who has joined a game.
@@ -70,20 +85,46 @@ type Player struct {
 	Username string
 	Hand     []*AnswerCard
 	Score    int
+
+	// Bot is set for players who aren't people, such as
+	// Rando Cardrissian.
+	Bot bool
+
+	// Mulliganed is set once the player has used their
+	// mulligan.
+	Mulliganed bool
+
+	// Eliminated is set once the player has been knocked out
+	// of a survival game. They stay on as a spectator.
+	Eliminated bool
 }
 
 // Game represents the state of a single game.
 type Game struct {
-	ID        int
-	Decks     []*Deck
-	Phase     Phase
-	MaxPoints int
-	Name      string
-	Owner     *Player
-	Password  string
-	PlayDeck  PlayDeck
-	Players   []Player
-	Round     *Round
+	ID       int
+	Decks    []*Deck
+	Phase    Phase
+	Name     string
+	Owner    *Player
+	Password string
+	PlayDeck PlayDeck
+	Players  []*Player
+	Round    *Round
+	Settings GameSettings
+
+	// History records the outcome of each finished round.
+	History []RoundResult
+
+	// Seed is the seed for all shuffling in the game. It is
+	// chosen when the game starts, unless already set.
+	Seed int64
+
+	// Deadline is when the current phase times out. It is
+	// zero if the phase has no time limit.
+	Deadline time.Time
+
+	random *Random
+	clock  Clock
 }
 
 // Create initialises a game in the `Lobby` state.
@@ -100,45 +141,94 @@ func Create(id int, name, password string, owner *Player) (game *Game, err error
 	}
 
 	game = &Game{
-		ID:        id,
-		MaxPoints: DefaultHandSize,
-		Name:      name,
-		Password:  password,
-		Phase:     Lobby,
+		ID:       id,
+		Name:     name,
+		Owner:    owner,
+		Password: password,
+		Phase:    Lobby,
+		Players:  []*Player{owner},
+		Settings: DefaultSettings(),
 	}
 
 	return game, nil
 }
 
-// Start moves the game state to `InProgress` and deals
+// Start moves the game state to `RoundInProgress` and deals
 // cards to joined players.
 func (g *Game) Start() (err error) {
-	if g.MaxPoints < 1 {
-		return errors.New("max points not set")
+	if g.Phase != Lobby {
+		return errors.New("game has already started")
 	}
 
-	g.PlayDeck.Init(g.Decks[0])
+	err = g.Settings.Validate()
+	if err != nil {
+		return err
+	}
 
-	g.DealAll(DefaultHandSize)
-	g.Phase = RoundInProgress
-	card, err := g.PlayDeck.DrawQuestion()
+	if len(g.Players) < MinPlayers {
+		return errors.New("not enough players to start")
+	}
+
+	if len(g.Decks) < 1 {
+		return errors.New("no decks selected")
+	}
+
+	// The deck is built and checked before anything changes,
+	// so that a game which fails to start is left as it was.
+	seed := g.Seed
+	if seed == 0 {
+		seed = NewSeed()
+	}
+	random := NewRandom(seed)
+	playDeck := PlayDeck{}
+	playDeck.Init(random, g.Settings.TagFilter, g.Decks...)
+	playDeck.AddBlanks(g.Settings.NumBlanks)
+
+	if playDeck.QuestionDeck.Remaining() < 1 {
+		return errors.New("selected decks have no question cards after filtering")
+	}
+
+	needed := g.Settings.HandSize * len(g.Players)
+	if available := playDeck.AnswerDeck.Remaining(); available < needed {
+		return fmt.Errorf("selected decks have %d answer cards, but %d players need at least %d", available, len(g.Players), needed)
+	}
+
+	g.Seed = seed
+	g.random = random
+	g.PlayDeck = playDeck
+	g.addBots()
+	g.DealAll(g.Settings.HandSize)
+
+	// Checked above, so there is a question to draw.
+	card, _ := g.PlayDeck.DrawQuestion()
+	g.setPhase(RoundInProgress)
 	g.Round = &Round{
-		Czar:     &g.Players[0],
+		Number:   1,
+		Czar:     g.czarAfter(nil),
 		Question: card,
 	}
+	g.dealExtras()
+	g.submitBots()
 	return
 }
 
 // DealAll deals cards to all joined players.
+//
+// Bots play from the top of the deck, and eliminated players
+// are out of play, so neither are dealt anything.
 func (g *Game) DealAll(upTo int) {
 	for _, player := range g.Players {
-		g.Deal(&player, 1)
+		if player.Bot || !player.active() {
+			continue
+		}
+		g.Deal(player, upTo)
 	}
 }
 
-// Deal deals cards to a single player.
+// Deal deals cards to a single player until their hand
+// holds `upTo` cards.
 func (g *Game) Deal(player *Player, upTo int) {
-	numNew := len(player.Hand) - upTo
+	numNew := upTo - len(player.Hand)
 	for i := 0; i < numNew; i++ {
 		card, err := g.PlayDeck.DrawAnswer()
 		if err != nil {
@@ -149,34 +239,579 @@ func (g *Game) Deal(player *Player, upTo int) {
 	}
 }
 
+// Player retrieves a joined player by ID.
+//
+// Returns nil if no such player has joined.
+func (g *Game) Player(id int) *Player {
+	for _, player := range g.Players {
+		if player.ID == id {
+			return player
+		}
+	}
+	return nil
+}
+
+// SubmitCards records a player's answer to the current
+// question.
+//
+// Exactly as many cards as the question picks must be
+// submitted, in the order they fill its blanks, and each must
+// be held in the player's hand. The cards are recorded as
+// held, except that blank cards must carry the text the player
+// has written on them, which is sanitised.
+//
+// Once every player other than the Czar has submitted,
+// the game moves on to `WinnerSelection`, or to `Voting`
+// if there is no Czar.
+func (g *Game) SubmitCards(playerID int, cards []AnswerCard) (err error) {
+	if g.Phase != RoundInProgress {
+		return errors.New("can't submit cards outside round in progress phase")
+	}
+
+	player := g.Player(playerID)
+	if player == nil {
+		return errors.New("player not in game")
+	}
+
+	if player == g.Round.Czar {
+		return errors.New("czar can't submit cards")
+	}
+
+	if !player.active() {
+		return errors.New("eliminated players can't submit cards")
+	}
+
+	if g.Round.Submission(playerID) != nil {
+		return errors.New("player has already submitted cards")
+	}
+
+	if len(cards) != g.Round.Question.Pick() {
+		return fmt.Errorf("must submit exactly %d cards", g.Round.Question.Pick())
+	}
+
+	played := make([]AnswerCard, 0, len(cards))
+	for i, card := range cards {
+		held := player.handCard(card)
+		if held == nil {
+			return fmt.Errorf("card %d is not in player's hand", i+1)
+		}
+
+		for _, other := range played {
+			if other.Is(card) {
+				return fmt.Errorf("card %d was submitted more than once", i+1)
+			}
+		}
+
+		text := held.Text
+		if held.Blank {
+			text, err = SanitiseBlankText(card.Text)
+			if err != nil {
+				return err
+			}
+		}
+
+		card = *held
+		card.Text = text
+		played = append(played, card)
+	}
+
+	for _, card := range played {
+		player.removeFromHand(card)
+	}
+
+	g.addSubmission(player, played)
+
+	g.checkSubmissions()
+	return
+}
+
+// SelectWinner awards the round to the player who made
+// the submission with the given ID.
+//
+// Only the Czar may select a winner. The game ends once
+// the winner reaches `MaxPoints`.
+func (g *Game) SelectWinner(czarID int, submissionID string) (err error) {
+	if g.Phase != WinnerSelection {
+		return errors.New("can't select winner outside winner selection phase")
+	}
+
+	if g.Round.Czar == nil || g.Round.Czar.ID != czarID {
+		return errors.New("only the czar may select a winner")
+	}
+
+	submission := g.Round.SubmissionByID(submissionID)
+	if submission == nil {
+		return errors.New("no such submission")
+	}
+
+	g.awardRound(submission)
+	return
+}
+
+// awardRound gives the round, and a point, to the player
+// who made the given submission.
+//
+// The game ends once the winner reaches `MaxPoints`, or in
+// survival mode once only one person is left in play.
+func (g *Game) awardRound(submission *CardSubmission) {
+	g.Round.Winner = submission.Player
+	g.Round.Winner.Score++
+
+	result := g.Round.result(submission)
+	if loser := g.eliminate(); loser != nil {
+		result.Eliminated = loser.ID
+	}
+	g.History = append(g.History, result)
+
+	switch {
+	case g.Survival() && g.survivor() != nil:
+		g.setPhase(EndOfGame)
+	case !g.Survival() && g.Round.Winner.Score >= g.Settings.MaxPoints:
+		g.setPhase(EndOfGame)
+	default:
+		g.setPhase(EndOfRound)
+	}
+}
+
+// NextRound discards the cards played in the previous
+// round, passes the Czar on to the next player, refills
+// hands and draws a new question.
+func (g *Game) NextRound() (err error) {
+	if g.Phase != EndOfRound {
+		return errors.New("can't start next round outside end of round phase")
+	}
+
+	return g.startRound(g.nextCzar())
+}
+
+// Join adds a player to the game.
+//
+// Players may only join during the lobby phase, while the
+// game isn't full, and must supply the game's password if
+// it has one.
+func (g *Game) Join(player *Player, password string) (err error) {
+	if player == nil {
+		return errors.New("must specify a player")
+	}
+
+	if g.Player(player.ID) != nil {
+		return errors.New("player has already joined")
+	}
+
+	if g.Phase != Lobby {
+		return errors.New("can't join game outside lobby phase")
+	}
+
+	if g.Password != "" && g.Password != password {
+		return errors.New("incorrect game password")
+	}
+
+	if len(g.Players) >= g.Settings.MaxPlayers {
+		return errors.New("game is full")
+	}
+
+	g.Players = append(g.Players, player)
+	return
+}
+
+// Leave removes a player from the game.
+//
+// If the game is in progress, the player's hand and
+// submission are discarded. A round whose Czar leaves is
+// abandoned, and the game ends once too few players remain.
+func (g *Game) Leave(playerID int) (err error) {
+	player := g.Player(playerID)
+	if player == nil {
+		return errors.New("player not in game")
+	}
+
+	next := g.seatAfter(player)
+	for i, p := range g.Players {
+		if p == player {
+			g.Players = append(g.Players[:i], g.Players[i+1:]...)
+			break
+		}
+	}
+
+	if g.Owner == player {
+		g.Owner = nil
+		for _, p := range g.Players {
+			if !p.Bot {
+				g.Owner = p
+				break
+			}
+		}
+	}
+
+	if g.Phase == Lobby || g.Phase == EndOfGame {
+		return
+	}
+
+	for _, card := range player.Hand {
+		g.PlayDeck.DiscardAnswer(*card)
+	}
+	player.Hand = nil
+
+	if g.tooFewPlayers() {
+		g.setPhase(EndOfGame)
+		return
+	}
+
+	if g.Round.Czar == player {
+		return g.startRound(next)
+	}
+
+	if g.Phase == EndOfRound {
+		return
+	}
+
+	for i, submission := range g.Round.CardSubmissions {
+		if submission.Player == player {
+			for _, card := range submission.Cards {
+				g.PlayDeck.DiscardAnswer(card)
+			}
+			g.Round.CardSubmissions = append(g.Round.CardSubmissions[:i], g.Round.CardSubmissions[i+1:]...)
+			break
+		}
+	}
+	g.Round.removeVotes(player)
+
+	switch g.Phase {
+	case RoundInProgress:
+		g.checkSubmissions()
+	case Voting:
+		g.checkVotes()
+	}
+	return
+}
+
+// Winner retrieves the player who won the game, who in
+// survival mode is the last one left in play.
+//
+// Returns nil if the game has not ended.
+func (g *Game) Winner() *Player {
+	if g.Phase != EndOfGame || g.Round == nil {
+		return nil
+	}
+
+	if g.Survival() {
+		return g.survivor()
+	}
+	return g.Round.Winner
+}
+
+// startRound discards the cards played in the current
+// round, trims or refills hands and begins a new round led
+// by the given Czar.
+func (g *Game) startRound(czar *Player) (err error) {
+	g.discardRound()
+
+	card, err := g.PlayDeck.DrawQuestion()
+	if err != nil {
+		return err
+	}
+
+	g.trimHands(g.Settings.HandSize)
+	g.DealAll(g.Settings.HandSize)
+	g.Round = &Round{
+		Number:   g.Round.Number + 1,
+		Czar:     czar,
+		Question: card,
+	}
+	g.setPhase(RoundInProgress)
+	g.dealExtras()
+	g.submitBots()
+	return
+}
+
+// dealExtras deals each player other than the Czar the
+// extra cards called for by the current question.
+func (g *Game) dealExtras() {
+	draw := g.Round.Question.Draw
+	if draw < 1 {
+		return
+	}
+
+	for _, player := range g.Players {
+		if player == g.Round.Czar || player.Bot || !player.active() {
+			continue
+		}
+		g.Deal(player, len(player.Hand)+draw)
+	}
+}
+
+// trimHands discards the most recently dealt cards from
+// hands holding more than `size` cards, such as those left
+// over from a question which had players draw extra.
+func (g *Game) trimHands(size int) {
+	for _, player := range g.Players {
+		for len(player.Hand) > size {
+			last := len(player.Hand) - 1
+			g.PlayDeck.DiscardAnswer(*player.Hand[last])
+			player.Hand = player.Hand[:last]
+		}
+	}
+}
+
+// allSubmitted reports whether every player other than
+// the Czar has submitted cards this round.
+//
+// Bots play as the round starts, if they can, so are never
+// waited on.
+func (g *Game) allSubmitted() bool {
+	for _, player := range g.Players {
+		if player.Bot || player == g.Round.Czar || !player.active() {
+			continue
+		}
+		if g.Round.Submission(player.ID) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// checkSubmissions moves the game on to judging once all
+// cards have been submitted.
+func (g *Game) checkSubmissions() {
+	if !g.allSubmitted() {
+		return
+	}
+	g.startJudging()
+}
+
+// startJudging moves the game on to `WinnerSelection`, or to
+// `Voting` if there is no Czar.
+func (g *Game) startJudging() {
+	if g.Round.Czar == nil {
+		g.setPhase(Voting)
+	} else {
+		g.setPhase(WinnerSelection)
+	}
+}
+
+// discardRound moves the question and submitted answers
+// of the current round onto their discard piles.
+func (g *Game) discardRound() {
+	if g.Round == nil {
+		return
+	}
+
+	if g.Round.Question != nil {
+		g.PlayDeck.DiscardQuestion(*g.Round.Question)
+	}
+
+	for _, submission := range g.Round.CardSubmissions {
+		for _, card := range submission.Cards {
+			g.PlayDeck.DiscardAnswer(card)
+		}
+	}
+}
+
+// nextCzar finds the player seated after the current Czar.
+func (g *Game) nextCzar() *Player {
+	return g.czarAfter(g.Round.Czar)
+}
+
+// czarAfter finds the Czar seated after the given player.
+//
+// Returns nil if the rules call for no Czar.
+func (g *Game) czarAfter(player *Player) *Player {
+	if g.Settings.Mode == GodIsDead {
+		return nil
+	}
+	return g.seatAfter(player)
+}
+
+// seatAfter finds the first player seated after the given
+// one who may act as Czar, passing over bots.
+//
+// Returns the first such player if the given one isn't in
+// the game, or nil if there are none.
+func (g *Game) seatAfter(player *Player) *Player {
+	start := 0
+	for i, p := range g.Players {
+		if p == player {
+			start = i + 1
+			break
+		}
+	}
+
+	for i := 0; i < len(g.Players); i++ {
+		p := g.Players[(start+i)%len(g.Players)]
+		if !p.Bot && p.active() && p != player {
+			return p
+		}
+	}
+	return nil
+}
+
+// handCard finds the given card in the player's hand.
+//
+// Returns nil if they don't hold it.
+func (p *Player) handCard(target AnswerCard) *AnswerCard {
+	for _, card := range p.Hand {
+		if card.Is(target) {
+			return card
+		}
+	}
+	return nil
+}
+
+// removeFromHand takes the given card out of the player's
+// hand, if they hold it.
+func (p *Player) removeFromHand(target AnswerCard) {
+	for i, card := range p.Hand {
+		if card.Is(target) {
+			p.Hand = append(p.Hand[:i], p.Hand[i+1:]...)
+			return
+		}
+	}
+}
+
 // Round represents the state of the current game round.
 type Round struct {
+	Number          int
 	CardSubmissions []CardSubmission
 	Czar            *Player
 	Question        *QuestionCard
+	Votes           []Vote
 	Winner          *Player
 }
 
+// Submission retrieves the cards submitted by a player
+// this round.
+//
+// Returns nil if the player has yet to submit.
+func (r *Round) Submission(playerID int) *CardSubmission {
+	for i := range r.CardSubmissions {
+		if r.CardSubmissions[i].Player.ID == playerID {
+			return &r.CardSubmissions[i]
+		}
+	}
+	return nil
+}
+
+// result records the outcome of the round won by the
+// given submission.
+func (r *Round) result(winner *CardSubmission) RoundResult {
+	result := RoundResult{
+		Number:     r.Number,
+		Czar:       playerID(r.Czar),
+		Winner:     winner.Player.ID,
+		WinnerName: winner.Player.Username,
+		WinnerBot:  winner.Player.Bot,
+		Cards:      winner.Cards,
+	}
+	if r.Question != nil {
+		result.Question = *r.Question
+		result.Sentence = RenderSubmission(*r.Question, winner.Cards)
+	}
+
+	for _, submission := range r.CardSubmissions {
+		for _, card := range submission.Cards {
+			if card.Blank {
+				result.Blanks = append(result.Blanks, WrittenBlank{
+					Player:   submission.Player.ID,
+					Username: submission.Player.Username,
+					Text:     card.Text,
+				})
+			}
+		}
+	}
+	return result
+}
+
+// RoundResult is the outcome of a finished round, with the
+// winning answers filled into the question.
+type RoundResult struct {
+	Number     int          `json:"number"`
+	Czar       int          `json:"czar"`
+	Winner     int          `json:"winner"`
+	WinnerName string       `json:"winnerName"`
+	WinnerBot  bool         `json:"winnerBot,omitempty"`
+	Eliminated int          `json:"eliminated,omitempty"`
+	Question   QuestionCard `json:"question"`
+	Cards      []AnswerCard `json:"cards"`
+	Sentence   string       `json:"sentence"`
+
+	// Blanks records the text written on every blank card
+	// submitted in the round, winning or not.
+	Blanks []WrittenBlank `json:"blanks,omitempty"`
+}
+
+// WrittenBlank is the text a player wrote on a blank card.
+type WrittenBlank struct {
+	Player   int    `json:"player"`
+	Username string `json:"username"`
+	Text     string `json:"text"`
+}
+
+// LastResult retrieves the outcome of the most recently
+// finished round.
+//
+// Returns nil if no round has finished yet.
+func (g *Game) LastResult() *RoundResult {
+	if len(g.History) < 1 {
+		return nil
+	}
+	return &g.History[len(g.History)-1]
+}
+
+// SubmissionByID retrieves a submission by its ID.
+//
+// Returns nil if there is no such submission.
+func (r *Round) SubmissionByID(id string) *CardSubmission {
+	for i := range r.CardSubmissions {
+		if r.CardSubmissions[i].ID == id {
+			return &r.CardSubmissions[i]
+		}
+	}
+	return nil
+}
+
+// addSubmission records the cards played by a player under
+// a new, random submission ID.
+//
+// IDs are drawn from the game's randomness, so that they say
+// nothing of who made the submission or when.
+func (g *Game) addSubmission(player *Player, cards []AnswerCard) {
+	g.Round.CardSubmissions = append(g.Round.CardSubmissions, CardSubmission{
+		ID:     g.newSubmissionID(g.PlayDeck.rand().Uint64),
+		Cards:  cards,
+		Player: player,
+	})
+}
+
+// newSubmissionID chooses a random ID which isn't used by any
+// submission this round, drawing values from `next`.
+func (g *Game) newSubmissionID(next func() uint64) (id string) {
+	for id == "" || g.Round.SubmissionByID(id) != nil {
+		id = fmt.Sprintf("%016x", next())
+	}
+	return
+}
+
 // CardSubmission represents a player's submission for their
 // answer to the Czar's question.
+//
+// The ID lets players judge the submission without knowing
+// who made it.
 type CardSubmission struct {
+	ID     string
 	Cards  []AnswerCard
 	Player *Player
 }
 
-// SetMaxPoints changes the number of points required to win
-// the game.
+// SetSeed changes the seed used for shuffling, so that the
+// game can be replayed.
 //
-// Will fail if the game is outside the lobby phase or if
-// the value isn't in the range [3, 10].
-func (g *Game) SetMaxPoints(maxPoints int) (err error) {
-	if maxPoints < 3 {
-		return errors.New("cannot have max points under 3")
-	} else if maxPoints >= 10 {
-		return errors.New("cannot have max points above 10")
+// Will fail if the game is outside the lobby phase.
+func (g *Game) SetSeed(seed int64) (err error) {
+	if g.Phase != Lobby {
+		return errors.New("can't change seed outside lobby phase")
 	}
 
-	g.MaxPoints = maxPoints
+	g.Seed = seed
 	return
 }
 
@@ -0,0 +1,448 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testDeck creates a deck with the given numbers of
+// single-answer question cards and answer cards.
+func testDeck(numQuestions, numAnswers int) *Deck {
+	deck := &Deck{ID: 1, Name: "Test"}
+	for i := 1; i <= numQuestions; i++ {
+		deck.QuestionCards = append(deck.QuestionCards, QuestionCard{
+			ID:         i,
+			DeckID:     deck.ID,
+			Text:       fmt.Sprintf("Question %d is ____.", i),
+			NumAnswers: 1,
+		})
+	}
+	for i := 1; i <= numAnswers; i++ {
+		deck.AnswerCards = append(deck.AnswerCards, AnswerCard{
+			ID:     i,
+			DeckID: deck.ID,
+			Text:   fmt.Sprintf("Answer %d.", i),
+		})
+	}
+	return deck
+}
+
+// newTestGame creates a game in its lobby with the given
+// number of players and a fixed seed, ready to start.
+func newTestGame(t *testing.T, numPlayers int, seed int64) *Game {
+	t.Helper()
+
+	g, err := Create(1, "Test game", "", &Player{ID: 1, Username: "player1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id := 2; id <= numPlayers; id++ {
+		err = g.Join(&Player{ID: id, Username: fmt.Sprintf("player%d", id)}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g.Decks = []*Deck{testDeck(20, 200)}
+	if err = g.SetSeed(seed); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// czarPick has the Czar choose the submission made by the
+// player seated after them.
+func czarPick(t *testing.T, g *Game) *Player {
+	t.Helper()
+
+	winner := g.seatAfter(g.Round.Czar)
+	submission := g.Round.Submission(winner.ID)
+	if submission == nil {
+		t.Fatalf("player %d has not submitted", winner.ID)
+	}
+	if err := g.SelectWinner(g.Round.Czar.ID, submission.ID); err != nil {
+		t.Fatal(err)
+	}
+	return winner
+}
+
+func TestPhases(t *testing.T) {
+	g := newTestGame(t, 3, 1)
+	g.Settings.MaxPoints = MinMaxPoints
+
+	if err := g.NextRound(); err == nil {
+		t.Error("started next round in lobby")
+	}
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Start(); err == nil {
+		t.Error("started game twice")
+	}
+
+	czars := []int{}
+	for round := 1; g.Phase != EndOfGame; round++ {
+		if round > 10 {
+			t.Fatal("game did not end")
+		}
+
+		if g.Phase != RoundInProgress || g.Round.Number != round {
+			t.Fatalf("in %v of round %d, want round %d in progress", g.Phase, g.Round.Number, round)
+		}
+		czar := g.Round.Czar
+		czars = append(czars, czar.ID)
+
+		for _, player := range g.Players {
+			want := g.Settings.HandSize
+			if len(player.Hand) != want {
+				t.Errorf("round %d: player %d holds %d cards, want %d", round, player.ID, len(player.Hand), want)
+			}
+		}
+
+		if err := g.SubmitCards(czar.ID, []AnswerCard{*czar.Hand[0]}); err == nil {
+			t.Error("czar submitted cards")
+		}
+		if err := g.NextRound(); err == nil {
+			t.Error("started next round with round in progress")
+		}
+
+		submitAll(t, g)
+		if g.Phase != WinnerSelection {
+			t.Fatalf("in %v after all submitted, want winner selection", g.Phase)
+		}
+
+		other := g.seatAfter(czar)
+		if err := g.SelectWinner(other.ID, g.Round.CardSubmissions[0].ID); err == nil {
+			t.Error("player other than czar selected winner")
+		}
+		if err := g.SelectWinner(czar.ID, "no such submission"); err == nil {
+			t.Error("czar selected missing submission")
+		}
+
+		winner := czarPick(t, g)
+		if g.Round.Winner != winner || g.LastResult().Winner != winner.ID {
+			t.Errorf("round %d went to %v, want player %d", round, g.Round.Winner, winner.ID)
+		}
+
+		if g.Phase == EndOfRound {
+			if err := g.NextRound(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	winner := g.Winner()
+	if winner == nil || winner.Score != g.Settings.MaxPoints {
+		t.Fatalf("game won by %v, want a player with %d points", winner, g.Settings.MaxPoints)
+	}
+	if len(g.History) != len(czars) {
+		t.Errorf("got %d results for %d rounds", len(g.History), len(czars))
+	}
+	for i := 1; i < len(czars); i++ {
+		if czars[i] == czars[i-1] {
+			t.Errorf("player %d was czar twice in a row", czars[i])
+		}
+	}
+	if err := g.NextRound(); err == nil {
+		t.Error("started next round after end of game")
+	}
+}
+
+func TestStartChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(g *Game)
+	}{
+		{"too few players", func(g *Game) { g.Players = g.Players[:MinPlayers-1] }},
+		{"no decks", func(g *Game) { g.Decks = nil }},
+		{"too few answers", func(g *Game) { g.Decks = []*Deck{testDeck(5, 10)} }},
+		{"no questions", func(g *Game) { g.Decks = []*Deck{testDeck(0, 100)} }},
+		{"invalid settings", func(g *Game) { g.Settings.HandSize = 0 }},
+		{"unseeded without questions", func(g *Game) {
+			g.Seed = 0
+			g.Decks = []*Deck{testDeck(0, 100)}
+		}},
+		{"too few answers with Rando", func(g *Game) {
+			g.Settings.HouseRules.Rando = true
+			g.Decks = []*Deck{testDeck(5, 10)}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, 3, 1)
+			tt.setup(g)
+			seed, players := g.Seed, len(g.Players)
+
+			if err := g.Start(); err == nil {
+				t.Error("game started")
+			}
+			if g.Phase != Lobby || g.Round != nil || !g.Deadline.IsZero() {
+				t.Errorf("phase is %v with round %v, want lobby without a round", g.Phase, g.Round)
+			}
+			if g.Seed != seed || g.random != nil {
+				t.Errorf("seed changed from %d to %d", seed, g.Seed)
+			}
+			if g.PlayDeck.QuestionDeck.Remaining() != 0 || g.PlayDeck.AnswerDeck.Remaining() != 0 {
+				t.Error("play deck was filled")
+			}
+			if len(g.Players) != players {
+				t.Errorf("%d players after failing to start, want %d", len(g.Players), players)
+			}
+		})
+	}
+}
+
+func TestStartWithoutCardsForRando(t *testing.T) {
+	g := newTestGame(t, 3, 1)
+	g.Settings.HouseRules.Rando = true
+	g.Decks = []*Deck{testDeck(5, g.Settings.HandSize*len(g.Players))}
+
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Round.Submission(RandoID) != nil {
+		t.Error("Rando played without cards to draw")
+	}
+
+	submitAll(t, g)
+	if g.Phase != WinnerSelection {
+		t.Errorf("in %v once everyone submitted, want winner selection", g.Phase)
+	}
+}
+
+// nonCzar finds the first player in play who isn't the Czar.
+func nonCzar(g *Game) *Player {
+	return g.seatAfter(g.Round.Czar)
+}
+
+// firstCards returns the first `n` cards of a player's hand.
+func firstCards(player *Player, n int) (cards []AnswerCard) {
+	for _, card := range player.Hand[:n] {
+		cards = append(cards, *card)
+	}
+	return
+}
+
+func TestActionsRejectedOutOfPhase(t *testing.T) {
+	tests := []struct {
+		name   string
+		phase  func(t *testing.T, g *Game)
+		action func(g *Game) error
+	}{
+		{
+			name: "submit twice",
+			phase: func(t *testing.T, g *Game) {
+				player := nonCzar(g)
+				if err := g.SubmitCards(player.ID, firstCards(player, 1)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			action: func(g *Game) error {
+				player := nonCzar(g)
+				return g.SubmitCards(player.ID, firstCards(player, 1))
+			},
+		},
+		{
+			name:  "submit too many cards",
+			phase: func(t *testing.T, g *Game) {},
+			action: func(g *Game) error {
+				player := nonCzar(g)
+				return g.SubmitCards(player.ID, firstCards(player, 2))
+			},
+		},
+		{
+			name:  "submit card not held",
+			phase: func(t *testing.T, g *Game) {},
+			action: func(g *Game) error {
+				return g.SubmitCards(nonCzar(g).ID, firstCards(g.Round.Czar, 1))
+			},
+		},
+		{
+			name:  "submit as stranger",
+			phase: func(t *testing.T, g *Game) {},
+			action: func(g *Game) error {
+				return g.SubmitCards(99, firstCards(nonCzar(g), 1))
+			},
+		},
+		{
+			name:  "select winner during round",
+			phase: func(t *testing.T, g *Game) {},
+			action: func(g *Game) error {
+				return g.SelectWinner(g.Round.Czar.ID, "")
+			},
+		},
+		{
+			name:  "submit during winner selection",
+			phase: submitAll,
+			action: func(g *Game) error {
+				return g.SubmitCards(g.Round.Czar.ID, firstCards(g.Round.Czar, 1))
+			},
+		},
+		{
+			name:  "next round during winner selection",
+			phase: submitAll,
+			action: func(g *Game) error {
+				return g.NextRound()
+			},
+		},
+		{
+			name: "select winner twice",
+			phase: func(t *testing.T, g *Game) {
+				submitAll(t, g)
+				czarPick(t, g)
+			},
+			action: func(g *Game) error {
+				return g.SelectWinner(g.Round.Czar.ID, g.Round.CardSubmissions[0].ID)
+			},
+		},
+		{
+			name: "submit at end of round",
+			phase: func(t *testing.T, g *Game) {
+				submitAll(t, g)
+				czarPick(t, g)
+			},
+			action: func(g *Game) error {
+				player := nonCzar(g)
+				return g.SubmitCards(player.ID, firstCards(player, 1))
+			},
+		},
+		{
+			name:  "join after start",
+			phase: func(t *testing.T, g *Game) {},
+			action: func(g *Game) error {
+				return g.Join(&Player{ID: 99, Username: "latecomer"}, "")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, 3, 1)
+			if err := g.Start(); err != nil {
+				t.Fatal(err)
+			}
+			tt.phase(t, g)
+
+			before := dealState(g)
+			phase := g.Phase
+			if err := tt.action(g); err == nil {
+				t.Fatal("action was allowed")
+			}
+			if g.Phase != phase {
+				t.Errorf("phase changed from %v to %v", phase, g.Phase)
+			}
+			if dealState(g) != before {
+				t.Errorf("state changed from %q to %q", before, dealState(g))
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	g, err := Create(1, "Test game", "secret", &Player{ID: 1, Username: "owner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Settings.MaxPlayers = MinPlayers
+
+	if err = g.Join(&Player{ID: 2, Username: "player2"}, "wrong"); err == nil {
+		t.Error("joined with wrong password")
+	}
+	if err = g.Join(&Player{ID: 2, Username: "player2"}, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err = g.Join(&Player{ID: 2, Username: "player2"}, "secret"); err == nil {
+		t.Error("joined twice")
+	}
+	if err = g.Join(&Player{ID: 3, Username: "player3"}, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err = g.Join(&Player{ID: 4, Username: "player4"}, "secret"); err == nil {
+		t.Error("joined full game")
+	}
+	if len(g.Players) != MinPlayers {
+		t.Errorf("game has %d players, want %d", len(g.Players), MinPlayers)
+	}
+}
+
+func TestLeave(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, g *Game)
+		who   func(g *Game) *Player
+		check func(t *testing.T, g *Game, left *Player)
+	}{
+		{
+			name:  "czar leaving restarts the round",
+			setup: func(t *testing.T, g *Game) { submitAll(t, g) },
+			who:   func(g *Game) *Player { return g.Round.Czar },
+			check: func(t *testing.T, g *Game, left *Player) {
+				if g.Phase != RoundInProgress || g.Round.Number != 2 || g.Round.Czar == left {
+					t.Errorf("in %v of round %d with czar %v", g.Phase, g.Round.Number, g.Round.Czar)
+				}
+			},
+		},
+		{
+			name:  "last player to submit leaving moves on",
+			setup: func(t *testing.T, g *Game) {},
+			who: func(g *Game) *Player {
+				for _, player := range g.Players {
+					if player != g.Round.Czar && player != g.Players[len(g.Players)-1] {
+						g.SubmitCards(player.ID, firstCards(player, 1))
+					}
+				}
+				return g.Players[len(g.Players)-1]
+			},
+			check: func(t *testing.T, g *Game, left *Player) {
+				if g.Phase != WinnerSelection {
+					t.Errorf("in %v, want winner selection", g.Phase)
+				}
+			},
+		},
+		{
+			name:  "submission is discarded",
+			setup: func(t *testing.T, g *Game) { submitAll(t, g) },
+			who:   nonCzar,
+			check: func(t *testing.T, g *Game, left *Player) {
+				if g.Round.Submission(left.ID) != nil || len(left.Hand) != 0 {
+					t.Error("submission or hand kept")
+				}
+			},
+		},
+		{
+			name: "too few players ends the game",
+			setup: func(t *testing.T, g *Game) {
+				if err := g.Leave(nonCzar(g).ID); err != nil {
+					t.Fatal(err)
+				}
+			},
+			who: nonCzar,
+			check: func(t *testing.T, g *Game, left *Player) {
+				if g.Phase != EndOfGame {
+					t.Errorf("in %v, want end of game", g.Phase)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, 4, 1)
+			if err := g.Start(); err != nil {
+				t.Fatal(err)
+			}
+			tt.setup(t, g)
+
+			left := tt.who(g)
+			if err := g.Leave(left.ID); err != nil {
+				t.Fatal(err)
+			}
+			if g.Player(left.ID) != nil {
+				t.Error("player still in game")
+			}
+			tt.check(t, g, left)
+		})
+	}
+}
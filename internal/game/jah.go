@@ -0,0 +1,451 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+)
+
+// MaxNumAnswers is the largest number of answers a question
+// card may ask for.
+const MaxNumAnswers int = 3
+
+// MaxDraw is the largest number of extra cards a question
+// card may have players draw.
+const MaxDraw int = 3
+
+// EntryError describes a malformed entry found while importing
+// cards.
+type EntryError struct {
+	// Kind is the kind of entry, such as "black card".
+	Kind string
+
+	// Index is the position of the entry in its list.
+	Index int
+
+	// Line is the line of the input on which the entry starts.
+	Line int
+
+	Err error
+}
+
+func (e *EntryError) Error() string {
+	return fmt.Sprintf("line %d: %s %d: %v", e.Line, e.Kind, e.Index, e.Err)
+}
+
+// ImportError lists all malformed entries found while
+// importing cards.
+type ImportError struct {
+	Entries []*EntryError
+}
+
+func (e *ImportError) Error() string {
+	lines := make([]string, 0, len(e.Entries))
+	for _, entry := range e.Entries {
+		lines = append(lines, entry.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// jahBlackCard is a black card in the JSON Against Humanity
+// format.
+type jahBlackCard struct {
+	Text string `json:"text"`
+	Pick int    `json:"pick"`
+	Pack *int   `json:"pack"`
+}
+
+// jahWhiteCard is a white card in the full JSON Against
+// Humanity format. The compact format uses plain strings.
+type jahWhiteCard struct {
+	Text string `json:"text"`
+	Pack *int   `json:"pack"`
+}
+
+// jahPack is a pack in the compact JSON Against Humanity
+// format, which refers to cards by index.
+type jahPack struct {
+	Name     string `json:"name"`
+	Official bool   `json:"official"`
+	White    []int  `json:"white"`
+	Black    []int  `json:"black"`
+}
+
+// ImportJSONAgainstHumanity reads decks exported in the JSON
+// Against Humanity format.
+//
+// Both the compact format, an object with `white`, `black`
+// and `packs` lists, and the full format, a list of packs
+// holding their own cards, are accepted. Each pack becomes a
+// deck. Compact exports without packs become a single deck.
+//
+// Malformed entries are skipped and reported together in an
+// `*ImportError`, alongside the decks built from the rest.
+func ImportJSONAgainstHumanity(r io.Reader) (decks []Deck, err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &jahParser{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	start := bytes.TrimLeft(data, " \t\r\n")
+	if len(start) > 0 && start[0] == '[' {
+		decks, err = p.parseFull()
+	} else {
+		decks, err = p.parseCompact()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.entryErrors) > 0 {
+		return decks, &ImportError{Entries: p.entryErrors}
+	}
+	return decks, nil
+}
+
+// jahParser walks a JSON Against Humanity export, keeping
+// track of entry positions for error reporting.
+type jahParser struct {
+	data        []byte
+	dec         *json.Decoder
+	entryErrors []*EntryError
+}
+
+func (p *jahParser) parseCompact() (decks []Deck, err error) {
+	var whites []string
+	var whiteValid []bool
+	var blacks []QuestionCard
+	var blackValid []bool
+	var packs []jahPack
+	var packLines []int
+
+	err = p.expectDelim('{')
+	if err != nil {
+		return nil, err
+	}
+
+	for p.dec.More() {
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+
+		switch key {
+		case "white":
+			err = p.eachEntry(func(index, line int) error {
+				text := ""
+				ok, err := p.decodeEntry("white card", index, line, &text)
+				if err != nil {
+					return err
+				}
+				whites = append(whites, text)
+				whiteValid = append(whiteValid, ok && p.checkText("white card", index, line, text))
+				return nil
+			})
+		case "black":
+			err = p.eachEntry(func(index, line int) error {
+				card := jahBlackCard{}
+				ok, err := p.decodeEntry("black card", index, line, &card)
+				if err != nil {
+					return err
+				}
+				question, valid := QuestionCard{}, false
+				if ok {
+					question, valid = p.questionCard("black card", card, index, line)
+				}
+				blacks = append(blacks, question)
+				blackValid = append(blackValid, valid)
+				return nil
+			})
+		case "packs":
+			err = p.eachEntry(func(index, line int) error {
+				pack := jahPack{}
+				ok, err := p.decodeEntry("pack", index, line, &pack)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					pack = jahPack{}
+				}
+				packs = append(packs, pack)
+				packLines = append(packLines, line)
+				return nil
+			})
+		default:
+			err = p.dec.Decode(&json.RawMessage{})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = p.expectDelim('}')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(packs) < 1 {
+		all := jahPack{Name: "Imported"}
+		for i := range whites {
+			all.White = append(all.White, i)
+		}
+		for i := range blacks {
+			all.Black = append(all.Black, i)
+		}
+		packs = append(packs, all)
+		packLines = append(packLines, 1)
+	}
+
+	for i, pack := range packs {
+		deck := Deck{
+			ID:            i + 1,
+			Name:          pack.Name,
+			AnswerCards:   []AnswerCard{},
+			QuestionCards: []QuestionCard{},
+		}
+
+		for _, index := range pack.White {
+			if index < 0 || index >= len(whites) {
+				p.entryError("pack", i, packLines[i], fmt.Errorf("white card index %d out of range", index))
+				continue
+			}
+			if !whiteValid[index] {
+				continue
+			}
+			deck.AnswerCards = append(deck.AnswerCards, AnswerCard{
+				ID:     len(deck.AnswerCards) + 1,
+				DeckID: deck.ID,
+				Text:   whites[index],
+			})
+		}
+
+		for _, index := range pack.Black {
+			if index < 0 || index >= len(blacks) {
+				p.entryError("pack", i, packLines[i], fmt.Errorf("black card index %d out of range", index))
+				continue
+			}
+			if !blackValid[index] {
+				continue
+			}
+			card := blacks[index]
+			card.ID = len(deck.QuestionCards) + 1
+			card.DeckID = deck.ID
+			deck.QuestionCards = append(deck.QuestionCards, card)
+		}
+
+		decks = append(decks, deck)
+	}
+
+	return decks, nil
+}
+
+func (p *jahParser) parseFull() (decks []Deck, err error) {
+	err = p.eachEntry(func(packIndex, packLine int) error {
+		deck := Deck{
+			ID:            packIndex + 1,
+			AnswerCards:   []AnswerCard{},
+			QuestionCards: []QuestionCard{},
+		}
+		whiteKind := fmt.Sprintf("pack %d white card", packIndex)
+		blackKind := fmt.Sprintf("pack %d black card", packIndex)
+
+		err := p.expectDelim('{')
+		if err != nil {
+			return err
+		}
+
+		for p.dec.More() {
+			key, err := p.key()
+			if err != nil {
+				return err
+			}
+
+			switch key {
+			case "name":
+				err = p.dec.Decode(&deck.Name)
+			case "white":
+				err = p.eachEntry(func(index, line int) error {
+					card := jahWhiteCard{}
+					ok, err := p.decodeEntry(whiteKind, index, line, &card)
+					if err != nil {
+						return err
+					}
+					if ok && p.checkText(whiteKind, index, line, card.Text) {
+						deck.AnswerCards = append(deck.AnswerCards, AnswerCard{
+							ID:     len(deck.AnswerCards) + 1,
+							DeckID: deck.ID,
+							Text:   card.Text,
+						})
+					}
+					return nil
+				})
+			case "black":
+				err = p.eachEntry(func(index, line int) error {
+					card := jahBlackCard{}
+					ok, err := p.decodeEntry(blackKind, index, line, &card)
+					if err != nil || !ok {
+						return err
+					}
+					question, valid := p.questionCard(blackKind, card, index, line)
+					if valid {
+						question.ID = len(deck.QuestionCards) + 1
+						question.DeckID = deck.ID
+						deck.QuestionCards = append(deck.QuestionCards, question)
+					}
+					return nil
+				})
+			default:
+				err = p.dec.Decode(&json.RawMessage{})
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		decks = append(decks, deck)
+		return p.expectDelim('}')
+	})
+	return decks, err
+}
+
+// decodeEntry decodes a single list entry.
+//
+// Entries of the wrong type are recorded as entry errors and
+// reported as not ok, while syntax errors are returned.
+func (p *jahParser) decodeEntry(kind string, index, line int, v interface{}) (ok bool, err error) {
+	err = p.dec.Decode(v)
+	if e, isType := err.(*json.UnmarshalTypeError); isType {
+		want := e.Type.String()
+		if e.Type.Kind() == reflect.Struct {
+			want = "object"
+		}
+		if e.Field != "" {
+			p.entryError(kind, index, line, fmt.Errorf("%s must be %s, got %s", e.Field, want, e.Value))
+		} else {
+			p.entryError(kind, index, line, fmt.Errorf("must be %s, got %s", want, e.Value))
+		}
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// questionCard converts a black card, recording an entry
+// error and reporting it as invalid if it is malformed.
+func (p *jahParser) questionCard(kind string, card jahBlackCard, index, line int) (question QuestionCard, valid bool) {
+	question = QuestionCard{
+		NumAnswers: card.Pick,
+		Text:       card.Text,
+	}
+
+	if !p.checkText(kind, index, line, card.Text) {
+		return question, false
+	}
+
+	if card.Pick < 1 || card.Pick > MaxNumAnswers {
+		p.entryError(kind, index, line, fmt.Errorf("pick must be between 1 and %d, got %d", MaxNumAnswers, card.Pick))
+		return question, false
+	}
+	return question, true
+}
+
+// checkText records an entry error if card text is empty.
+func (p *jahParser) checkText(kind string, index, line int, text string) bool {
+	if strings.TrimSpace(text) == "" {
+		p.entryError(kind, index, line, errors.New("text is empty"))
+		return false
+	}
+	return true
+}
+
+// eachEntry reads a JSON array, calling fn to decode each
+// element with its index and starting line.
+func (p *jahParser) eachEntry(fn func(index, line int) error) (err error) {
+	err = p.expectDelim('[')
+	if err != nil {
+		return err
+	}
+
+	for index := 0; p.dec.More(); index++ {
+		line := p.line(p.dec.InputOffset())
+		err = fn(index, line)
+		if err != nil {
+			return p.syntaxError(err)
+		}
+	}
+
+	return p.expectDelim(']')
+}
+
+// key reads an object key.
+func (p *jahParser) key() (key string, err error) {
+	line := p.line(p.dec.InputOffset())
+	tok, err := p.dec.Token()
+	if err != nil {
+		return "", p.syntaxError(err)
+	}
+
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("line %d: expected object key", line)
+	}
+	return key, nil
+}
+
+// expectDelim reads a delimiter, failing if any other token
+// is found.
+func (p *jahParser) expectDelim(delim json.Delim) (err error) {
+	line := p.line(p.dec.InputOffset())
+	tok, err := p.dec.Token()
+	if err != nil {
+		return p.syntaxError(err)
+	}
+
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("line %d: expected %q", line, delim)
+	}
+	return nil
+}
+
+// syntaxError adds line context to errors from the decoder.
+func (p *jahParser) syntaxError(err error) error {
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		return fmt.Errorf("line %d: %v", p.line(e.Offset), e)
+	case *json.UnmarshalTypeError:
+		return fmt.Errorf("line %d: %v", p.line(e.Offset), e)
+	case *EntryError:
+		return e
+	}
+	if err == io.EOF {
+		return errors.New("unexpected end of input")
+	}
+	return err
+}
+
+// entryError records a malformed entry.
+func (p *jahParser) entryError(kind string, index, line int, err error) {
+	p.entryErrors = append(p.entryErrors, &EntryError{
+		Kind:  kind,
+		Index: index,
+		Line:  line,
+		Err:   err,
+	})
+}
+
+// line finds the line of the first token at or after the
+// given input offset.
+func (p *jahParser) line(offset int64) int {
+	if offset > int64(len(p.data)) {
+		offset = int64(len(p.data))
+	}
+
+	rest := p.data[offset:]
+	skipped := len(rest) - len(bytes.TrimLeft(rest, " \t\r\n,:"))
+	return bytes.Count(p.data[:offset+int64(skipped)], []byte("\n")) + 1
+}
@@ -0,0 +1,180 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const jahCompact = `{
+  "black": [
+    {"text": "Why can't I sleep at night?",
+     "pick": 1},
+    {"text": "____ + ____ = ____.", "pick": 3}
+  ],
+  "white": [
+    "Bees?",
+    "Vigorous jazz hands.",
+    "Oprah."
+  ],
+  "packs": [
+    {"name": "Base", "official": true, "white": [0, 1], "black": [0]},
+    {"name": "Extra", "white": [2], "black": [1]}
+  ]
+}`
+
+const jahFull = `[
+  {
+    "name": "Base",
+    "black": [{"text": "Why can't I sleep at night?", "pick": 1}],
+    "white": [{"text": "Bees?"}, {"text": "Vigorous jazz hands."}]
+  },
+  {
+    "name": "Extra",
+    "white": [{"text": "Oprah.", "pack": 1}],
+    "black": [{"text": "____ + ____ = ____.", "pick": 3}]
+  }
+]`
+
+func TestImportJSONAgainstHumanity(t *testing.T) {
+	base := Deck{
+		ID:   1,
+		Name: "Base",
+		QuestionCards: []QuestionCard{
+			{ID: 1, DeckID: 1, NumAnswers: 1, Text: "Why can't I sleep at night?"},
+		},
+		AnswerCards: []AnswerCard{
+			{ID: 1, DeckID: 1, Text: "Bees?"},
+			{ID: 2, DeckID: 1, Text: "Vigorous jazz hands."},
+		},
+	}
+	extra := Deck{
+		ID:   2,
+		Name: "Extra",
+		QuestionCards: []QuestionCard{
+			{ID: 1, DeckID: 2, NumAnswers: 3, Text: "____ + ____ = ____."},
+		},
+		AnswerCards: []AnswerCard{
+			{ID: 1, DeckID: 2, Text: "Oprah."},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		in      string
+		want    []Deck
+		wantErr string
+	}{
+		{
+			name: "compact",
+			in:   jahCompact,
+			want: []Deck{base, extra},
+		},
+		{
+			name: "full",
+			in:   jahFull,
+			want: []Deck{base, extra},
+		},
+		{
+			name: "compact without packs",
+			in:   `{"black": [{"text": "Why? ____", "pick": 1}], "white": ["Bees?"], "order": ["ignored"]}`,
+			want: []Deck{{
+				ID:            1,
+				Name:          "Imported",
+				QuestionCards: []QuestionCard{{ID: 1, DeckID: 1, NumAnswers: 1, Text: "Why? ____"}},
+				AnswerCards:   []AnswerCard{{ID: 1, DeckID: 1, Text: "Bees?"}},
+			}},
+		},
+		{
+			name: "compact malformed entries",
+			in: `{
+  "black": [
+    {"text": "Why can't I sleep at night?",
+     "pick": 1},
+    {"text": "____ + ____ = ____.", "pick": 9},
+    {"text": "", "pick": 1},
+    {"text": "What is ____?", "pick": "two"}
+  ],
+  "white": ["Bees?", 3, "  "],
+  "packs": [
+    {"name": "Base", "white": [0, 1, 2, 7], "black": [0, 1, 2, 3, -1]}
+  ]
+}`,
+			want: []Deck{{
+				ID:            1,
+				Name:          "Base",
+				QuestionCards: []QuestionCard{{ID: 1, DeckID: 1, NumAnswers: 1, Text: "Why can't I sleep at night?"}},
+				AnswerCards:   []AnswerCard{{ID: 1, DeckID: 1, Text: "Bees?"}},
+			}},
+			wantErr: strings.Join([]string{
+				"line 5: black card 1: pick must be between 1 and 3, got 9",
+				"line 6: black card 2: text is empty",
+				"line 7: black card 3: pick must be int, got string",
+				"line 9: white card 1: must be string, got number",
+				"line 9: white card 2: text is empty",
+				"line 11: pack 0: white card index 7 out of range",
+				"line 11: pack 0: black card index -1 out of range",
+			}, "\n"),
+		},
+		{
+			name: "full malformed entries",
+			in: `[
+  {
+    "name": "Base",
+    "white": [{"text": "Bees?"}, "Oprah.", {"text": ""}],
+    "black": [
+      {"text": "Why can't I sleep at night?", "pick": 0},
+      {"text": "What is ____?", "pick": 1}
+    ]
+  }
+]`,
+			want: []Deck{{
+				ID:            1,
+				Name:          "Base",
+				QuestionCards: []QuestionCard{{ID: 1, DeckID: 1, NumAnswers: 1, Text: "What is ____?"}},
+				AnswerCards:   []AnswerCard{{ID: 1, DeckID: 1, Text: "Bees?"}},
+			}},
+			wantErr: strings.Join([]string{
+				"line 4: pack 0 white card 1: must be object, got string",
+				"line 4: pack 0 white card 2: text is empty",
+				"line 6: pack 0 black card 0: pick must be between 1 and 3, got 0",
+			}, "\n"),
+		},
+		{
+			name:    "truncated",
+			in:      "{\n  \"white\": [\"Bees?\"",
+			wantErr: "line 2: unexpected end of JSON input",
+		},
+		{
+			name:    "syntax error",
+			in:      "{\n  \"white\": [\"Bees?\" \"Oprah.\"]\n}",
+			wantErr: "line 2: invalid character '\"' after array element",
+		},
+		{
+			name:    "list where object expected",
+			in:      "[\n  [\"Bees?\"]\n]",
+			wantErr: "line 2: expected \"{\"",
+		},
+		{
+			name:    "string where list expected",
+			in:      "{\n  \"white\": \"Bees?\"\n}",
+			wantErr: "line 2: expected \"[\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ImportJSONAgainstHumanity(strings.NewReader(tt.in))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("got error:\n%v\nwant:\n%s", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got decks %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+)
+
+// Random is a seeded source of randomness whose state can
+// be recorded and restored, so that games can be replayed.
+type Random struct {
+	*rand.Rand
+	source *countingSource
+}
+
+// NewRandom creates a source of randomness from a seed.
+//
+// Games created with the same seed and played with the same
+// actions will deal exactly the same cards.
+func NewRandom(seed int64) *Random {
+	return restoreRandom(seed, 0)
+}
+
+// NewSeed creates a seed from a cryptographically secure
+// source, for use outside of tests and replays.
+func NewSeed() int64 {
+	return int64(secureUint64())
+}
+
+// secureUint64 draws a value from a cryptographically secure
+// source, leaving the game's own randomness untouched.
+func secureUint64() uint64 {
+	b := [8]byte{}
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// Seed returns the seed the source was created from.
+func (r *Random) Seed() int64 {
+	return r.source.seed
+}
+
+// Calls returns the number of values drawn from the source.
+func (r *Random) Calls() uint64 {
+	return r.source.calls
+}
+
+// restoreRandom recreates a source of randomness in the state
+// it was in after `calls` values had been drawn from it.
+func restoreRandom(seed int64, calls uint64) *Random {
+	source := &countingSource{
+		src:  rand.NewSource(seed).(rand.Source64),
+		seed: seed,
+	}
+	for source.calls < calls {
+		source.Uint64()
+	}
+	return &Random{Rand: rand.New(source), source: source}
+}
+
+// countingSource is a random source which keeps count of the
+// values drawn from it.
+type countingSource struct {
+	src   rand.Source64
+	seed  int64
+	calls uint64
+}
+
+func (s *countingSource) Int63() int64 {
+	s.calls++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.calls++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed = seed
+	s.calls = 0
+}
@@ -0,0 +1,149 @@
+package game
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// dealState records the cards dealt and played in a game, to
+// compare one replay with another.
+func dealState(g *Game) string {
+	state := fmt.Sprintf("round %d, question %d:", g.Round.Number, g.Round.Question.ID)
+	for _, player := range g.Players {
+		state += fmt.Sprintf(" player %d [", player.ID)
+		for _, card := range player.Hand {
+			state += fmt.Sprintf(" %d", card.ID)
+		}
+		state += " ]"
+	}
+	for _, submission := range g.Round.CardSubmissions {
+		state += fmt.Sprintf(" %s by %d", submission.ID, submission.Player.ID)
+	}
+	return state
+}
+
+// playRound plays a round of a game, recording the state after
+// each step.
+func playRound(t *testing.T, g *Game) (states []string) {
+	t.Helper()
+
+	states = append(states, dealState(g))
+	submitAll(t, g)
+	states = append(states, dealState(g))
+	czarPick(t, g)
+	if g.Phase == EndOfRound {
+		if err := g.NextRound(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return states
+}
+
+func TestReplay(t *testing.T) {
+	a := newTestGame(t, 4, 42)
+	b := newTestGame(t, 4, 42)
+	other := newTestGame(t, 4, 43)
+	for _, g := range []*Game{a, b, other} {
+		if err := g.Start(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if dealState(a) == dealState(other) {
+		t.Error("different seeds dealt the same cards")
+	}
+
+	for round := 0; round < 2; round++ {
+		if got, want := playRound(t, b), playRound(t, a); !reflect.DeepEqual(got, want) {
+			t.Fatalf("round %d replayed as %q, want %q", round+1, got, want)
+		}
+	}
+
+	data, err := MarshalSnapshot(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := UnmarshalSnapshot(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := dealState(restored), dealState(a); got != want {
+		t.Fatalf("restored as %q, want %q", got, want)
+	}
+
+	for a.Phase != EndOfGame {
+		want := playRound(t, a)
+		if got := playRound(t, b); !reflect.DeepEqual(got, want) {
+			t.Fatalf("replay diverged: got %q, want %q", got, want)
+		}
+		if got := playRound(t, restored); !reflect.DeepEqual(got, want) {
+			t.Fatalf("restored game diverged: got %q, want %q", got, want)
+		}
+	}
+
+	if b.Phase != EndOfGame || restored.Phase != EndOfGame {
+		t.Errorf("replays ended in %v and %v", b.Phase, restored.Phase)
+	}
+}
+
+func TestRandomRestore(t *testing.T) {
+	original := NewRandom(7)
+	for i := 0; i < 10; i++ {
+		original.Intn(100)
+	}
+	original.Uint64()
+
+	restored := restoreRandom(original.Seed(), original.Calls())
+	if restored.Calls() != original.Calls() {
+		t.Fatalf("restored after %d calls, want %d", restored.Calls(), original.Calls())
+	}
+
+	for i := 0; i < 10; i++ {
+		if got, want := restored.Int63(), original.Int63(); got != want {
+			t.Fatalf("value %d is %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestShuffleIsSeeded(t *testing.T) {
+	order := func(seed int64) (ids []int) {
+		deck := CardDeck[AnswerCard]{}
+		deck.Init(testDeck(0, 50).AnswerCards)
+		deck.Shuffle(NewRandom(seed).Rand)
+		for deck.Remaining() > 0 {
+			card, _ := deck.Draw()
+			ids = append(ids, card.ID)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(order(1), order(1)) {
+		t.Error("same seed shuffled differently")
+	}
+	if reflect.DeepEqual(order(1), order(2)) {
+		t.Error("different seeds shuffled the same")
+	}
+}
+
+func TestSeed(t *testing.T) {
+	g := newTestGame(t, 3, 0)
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Seed == 0 {
+		t.Error("no seed chosen on start")
+	}
+	if err := g.SetSeed(5); err == nil {
+		t.Error("seed changed after start")
+	}
+
+	fixed := newTestGame(t, 3, 5)
+	if err := fixed.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if fixed.Seed != 5 {
+		t.Errorf("seed is %d, want 5", fixed.Seed)
+	}
+}
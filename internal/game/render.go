@@ -0,0 +1,181 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// articles are always lowercased when an answer is placed
+// mid-sentence, even before a name, as in "the Pope".
+var articles = map[string]bool{"a": true, "an": true, "the": true}
+
+// lowercaseStarters are other words which begin many answers.
+// They are lowercased mid-sentence unless followed by a
+// capitalised word, as in "Her Majesty the Queen".
+var lowercaseStarters = map[string]bool{
+	"my": true, "your": true, "his": true, "her": true, "its": true,
+	"our": true, "their": true, "some": true, "being": true,
+	"getting": true, "this": true, "that": true,
+}
+
+// abbreviations are words which keep their full stop, even at
+// the end of an answer.
+var abbreviations = map[string]bool{
+	"etc": true, "mr": true, "mrs": true, "ms": true, "dr": true,
+	"st": true, "jr": true, "sr": true, "inc": true, "ltd": true,
+	"co": true, "vs": true,
+}
+
+// RenderSubmission builds the sentence formed by filling the
+// blanks of a question with answers, in order.
+//
+// Answers are capitalised at the start of a sentence. Mid-sentence,
+// answers opening with an ordinary word are lowercased, while names
+// and acronyms are left alone. An answer's trailing full stop is
+// dropped where more of the question follows, unless it ends an
+// abbreviation, in which case a full stop following the blank is
+// dropped instead. So is the question's own punctuation after an
+// answer ending in `?` or `!`. Answers to questions without blanks,
+// or beyond the number of blanks, are appended.
+func RenderSubmission(question QuestionCard, answers []AnswerCard) string {
+	text := question.Text
+	blanks := blankPattern.FindAllStringIndex(text, -1)
+
+	b := strings.Builder{}
+	last := 0
+	used := 0
+	for _, blank := range blanks {
+		b.WriteString(text[last:blank[0]])
+		last = blank[1]
+
+		if used >= len(answers) {
+			b.WriteString(text[blank[0]:blank[1]])
+			continue
+		}
+
+		answer := strings.TrimSpace(answers[used].Text)
+		used++
+
+		if strings.HasSuffix(answer, ".") && strings.TrimSpace(text[last:]) != "" {
+			if !endsWithAbbreviation(answer) {
+				answer = strings.TrimRight(answer, ".")
+			} else if text[last] == '.' {
+				last++
+			}
+		}
+
+		if isSentenceStart(b.String()) {
+			answer = capitalise(answer)
+		} else {
+			answer = lowercaseOpening(answer)
+		}
+
+		if strings.HasSuffix(answer, "?") || strings.HasSuffix(answer, "!") {
+			if last < len(text) && strings.IndexByte(".?!", text[last]) >= 0 {
+				last++
+			}
+		}
+		b.WriteString(answer)
+	}
+	b.WriteString(text[last:])
+
+	for _, card := range answers[used:] {
+		sentence := strings.TrimRight(b.String(), " ")
+		b.Reset()
+		b.WriteString(sentence)
+		b.WriteString(" ")
+		b.WriteString(capitalise(strings.TrimSpace(card.Text)))
+	}
+
+	return b.String()
+}
+
+// isSentenceStart reports whether text following the given
+// text would begin a new sentence.
+func isSentenceStart(before string) bool {
+	before = strings.TrimRightFunc(before, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || r == '\'' || r == '(' || r == '“'
+	})
+	if before == "" {
+		return true
+	}
+
+	r, _ := utf8.DecodeLastRuneInString(before)
+	return r == '.' || r == '!' || r == '?' || r == ':'
+}
+
+// capitalise upper-cases the first letter of text.
+func capitalise(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	if r == utf8.RuneError {
+		return text
+	}
+	return string(unicode.ToUpper(r)) + text[size:]
+}
+
+// lowercaseOpening lower-cases the first letter of text if it
+// opens with an ordinary word rather than a name.
+//
+// Articles and common opening words are lowercased, as are
+// capitalised words followed only by lowercase ones. Single
+// words, acronyms, "I" and words followed by a name are left
+// as they are, as they may be names themselves.
+func lowercaseOpening(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) < 1 || !isCapitalised(words[0]) {
+		return text
+	}
+
+	first := strings.ToLower(words[0])
+	switch {
+	case articles[first]:
+	case lowercaseStarters[first]:
+		if len(words) > 1 && startsUpper(words[1]) {
+			return text
+		}
+	case len(words) < 2:
+		return text
+	default:
+		for _, word := range words[1:] {
+			if startsUpper(word) {
+				return text
+			}
+		}
+	}
+
+	i := strings.IndexFunc(text, unicode.IsLetter)
+	r, size := utf8.DecodeRuneInString(text[i:])
+	return text[:i] + string(unicode.ToLower(r)) + text[i+size:]
+}
+
+// isCapitalised reports whether a word is an upper-case letter
+// followed only by lower-case ones, other than "I".
+func isCapitalised(word string) bool {
+	if word == "I" || !startsUpper(word) {
+		return false
+	}
+	_, size := utf8.DecodeRuneInString(word)
+	return strings.IndexFunc(word[size:], unicode.IsUpper) < 0
+}
+
+// startsUpper reports whether a word begins with an upper-case
+// letter, other than the word "I".
+func startsUpper(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return word != "I" && unicode.IsUpper(r)
+}
+
+// endsWithAbbreviation reports whether the last word of text
+// is an abbreviation, such as "etc." or "D.C.".
+func endsWithAbbreviation(text string) bool {
+	words := strings.Fields(text)
+	if len(words) < 1 {
+		return false
+	}
+
+	word := strings.TrimSuffix(words[len(words)-1], ".")
+	return strings.Contains(word, ".") || abbreviations[strings.ToLower(word)]
+}
@@ -0,0 +1,55 @@
+package game
+
+import "testing"
+
+func TestRenderSubmission(t *testing.T) {
+	answers := func(texts ...string) (cards []AnswerCard) {
+		for _, text := range texts {
+			cards = append(cards, AnswerCard{Text: text})
+		}
+		return
+	}
+
+	tests := []struct {
+		name     string
+		question string
+		answers  []AnswerCard
+		want     string
+	}{
+		{"starter lowercased", "I drink to forget ____.", answers("A windmill full of corpses."), "I drink to forget a windmill full of corpses."},
+		{"name kept", "I drink to forget ____.", answers("Oprah."), "I drink to forget Oprah."},
+		{"single capital kept", "Why can't I sleep at night? ____.", answers("Batman."), "Why can't I sleep at night? Batman."},
+		{"name kept mid-sentence", "____ is my hero, after ____.", answers("being on fire.", "Batman."), "Being on fire is my hero, after Batman."},
+		{"title kept", "And the Academy Award for ____ goes to ____.", answers("Jesus.", "Bees."), "And the Academy Award for Jesus goes to Bees."},
+		{"sentence start capitalised", "____ is how I want to die.", answers("being on fire."), "Being on fire is how I want to die."},
+		{"question without blank", "What's that smell?", answers("Bees?"), "What's that smell? Bees?"},
+		{"answer question mark replaces full stop", "I drink to forget ____.", answers("Bees?"), "I drink to forget Bees?"},
+		{"answer question mark replaces exclamation", "It's ____!", answers("Bees?"), "It's Bees?"},
+		{"answer exclamation replaces question mark", "Who ate ____?", answers("the Pope!"), "Who ate the Pope!"},
+		{"several blanks", "____ + ____ = ____.", answers("Barack Obama.", "The Pope.", "Bees."), "Barack Obama + the Pope = Bees."},
+		{"blank after colon", "Step 1: ____. Step 2: ____.", answers("the pope.", "profit."), "Step 1: The pope. Step 2: Profit."},
+		{"extra answers appended", "Make a haiku.", answers("a.", "b.", "c."), "Make a haiku. A. B. C."},
+		{"missing answers leave blanks", "Only ____ and ____.", answers("Bees."), "Only Bees and ____."},
+		{"full stop kept at end of question", "What's the next Happy Meal toy? ____", answers("Vigorous jazz hands."), "What's the next Happy Meal toy? Vigorous jazz hands."},
+		{"full stop dropped before comma", "____, obviously.", answers("Bees."), "Bees, obviously."},
+		{"ordinary words lowercased", "I drink to forget ____.", answers("Vigorous jazz hands."), "I drink to forget vigorous jazz hands."},
+		{"ordinary words lowercased around I", "Who ate ____?", answers("Everything I own."), "Who ate everything I own?"},
+		{"possessive lowercased", "I drink to forget ____.", answers("My inner demons."), "I drink to forget my inner demons."},
+		{"possessive before name kept", "I drink to forget ____.", answers("Her Majesty the Queen."), "I drink to forget Her Majesty the Queen."},
+		{"full name kept", "I drink to forget ____.", answers("Barack Obama."), "I drink to forget Barack Obama."},
+		{"acronym kept", "I drink to forget ____.", answers("NASA budget cuts."), "I drink to forget NASA budget cuts."},
+		{"I kept", "I drink to forget ____.", answers("I am the walrus."), "I drink to forget I am the walrus."},
+		{"abbreviation replaces full stop", "I drink to forget ____.", answers("Washington, D.C."), "I drink to forget Washington, D.C."},
+		{"abbreviation kept mid-sentence", "____ is my hero.", answers("Bees, wasps, etc."), "Bees, wasps, etc. is my hero."},
+		{"abbreviation before comma", "Thanks to ____, I'm rich.", answers("Acme Inc."), "Thanks to Acme Inc., I'm rich."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderSubmission(QuestionCard{Text: tt.question}, tt.answers)
+			if got != tt.want {
+				t.Errorf("RenderSubmission(%q) = %q, want %q", tt.question, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package game
+
+import (
+	"errors"
+	"log"
+)
+
+const (
+	// RandoID is the player ID of Rando Cardrissian.
+	RandoID int = -1
+
+	// RandoName is the username of Rando Cardrissian.
+	RandoName string = "Rando Cardrissian"
+)
+
+// HouseRules are optional rules which change how a game
+// is played.
+type HouseRules struct {
+	// Rando adds Rando Cardrissian, a bot which plays random
+	// cards from the top of the answer deck each round.
+	Rando bool `json:"rando"`
+
+	// RebootUniverse lets players trade a point for a fresh
+	// hand.
+	RebootUniverse bool `json:"rebootUniverse"`
+
+	// Mulligan lets each player trade in their hand for free,
+	// once per game, during the first round.
+	Mulligan bool `json:"mulligan"`
+}
+
+// RebootUniverse trades one of a player's points for a
+// fresh hand.
+//
+// Only allowed under the house rule, during a round and
+// before the player has submitted.
+func (g *Game) RebootUniverse(playerID int) (err error) {
+	if !g.Settings.HouseRules.RebootUniverse {
+		return errors.New("rebooting the universe is not allowed in this game")
+	}
+
+	player, err := g.handReplacer(playerID)
+	if err != nil {
+		return err
+	}
+
+	if player.Score < 1 {
+		return errors.New("rebooting the universe costs a point")
+	}
+
+	player.Score--
+	g.replaceHand(player)
+	return
+}
+
+// Mulligan trades in a player's hand for a fresh one.
+//
+// Only allowed under the house rule, once per game, during
+// the first round and before the player has submitted.
+func (g *Game) Mulligan(playerID int) (err error) {
+	if !g.Settings.HouseRules.Mulligan {
+		return errors.New("mulligans are not allowed in this game")
+	}
+
+	player, err := g.handReplacer(playerID)
+	if err != nil {
+		return err
+	}
+
+	if g.Round.Number != 1 {
+		return errors.New("mulligans are only allowed in the first round")
+	}
+
+	if player.Mulliganed {
+		return errors.New("player has already taken a mulligan")
+	}
+
+	player.Mulliganed = true
+	g.replaceHand(player)
+	return
+}
+
+// handReplacer finds a player who may replace their hand.
+func (g *Game) handReplacer(playerID int) (*Player, error) {
+	if g.Phase != RoundInProgress {
+		return nil, errors.New("can't replace hand outside round in progress phase")
+	}
+
+	player := g.Player(playerID)
+	if player == nil || player.Bot {
+		return nil, errors.New("player not in game")
+	}
+
+	if !player.active() {
+		return nil, errors.New("eliminated players have no hand")
+	}
+
+	if g.Round.Submission(playerID) != nil {
+		return nil, errors.New("can't replace hand after submitting cards")
+	}
+	return player, nil
+}
+
+// replaceHand deals a player a fresh hand, then discards
+// their old one so that it can't be dealt straight back.
+//
+// The new hand includes any extra cards the current question
+// has players other than the Czar draw.
+func (g *Game) replaceHand(player *Player) {
+	size := g.Settings.HandSize
+	if player != g.Round.Czar {
+		size += g.Round.Question.Draw
+	}
+
+	old := player.Hand
+	player.Hand = nil
+	g.Deal(player, size)
+
+	for _, card := range old {
+		g.PlayDeck.DiscardAnswer(*card)
+	}
+}
+
+// NumHumans counts the players in the game who aren't bots.
+func (g *Game) NumHumans() (n int) {
+	for _, player := range g.Players {
+		if !player.Bot {
+			n++
+		}
+	}
+	return
+}
+
+// addBots seats the bots called for by the house rules.
+func (g *Game) addBots() {
+	if g.Settings.HouseRules.Rando && g.Player(RandoID) == nil {
+		g.Players = append(g.Players, &Player{
+			ID:       RandoID,
+			Username: RandoName,
+			Bot:      true,
+		})
+	}
+}
+
+// submitBots makes a submission for each bot in the game,
+// played from the top of the answer deck.
+//
+// Blank cards are passed over, as bots can't write on them.
+// A bot which finds too few other cards sits the round out,
+// rather than playing fewer cards than the question asks for.
+func (g *Game) submitBots() {
+	for _, player := range g.Players {
+		if !player.Bot || player == g.Round.Czar {
+			continue
+		}
+
+		cards, err := g.drawForBot()
+		if err != nil {
+			log.Printf("Game %d: %s sits the round out: %v", g.ID, player.Username, err)
+			for _, card := range cards {
+				g.PlayDeck.DiscardAnswer(card)
+			}
+			continue
+		}
+		g.addSubmission(player, cards)
+	}
+}
+
+// drawForBot draws enough cards other than blanks from the top
+// of the answer deck to answer the current question.
+//
+// On failure, the cards drawn so far are returned along with
+// the error.
+func (g *Game) drawForBot() (cards []AnswerCard, err error) {
+	skipped := 0
+	for len(cards) < g.Round.Question.Pick() {
+		card, err := g.PlayDeck.DrawAnswer()
+		if err != nil {
+			return cards, err
+		}
+
+		if card.Blank {
+			g.PlayDeck.DiscardAnswer(*card)
+			skipped++
+			if skipped > MaxBlankCards {
+				return cards, errors.New("found only blank cards")
+			}
+			continue
+		}
+		cards = append(cards, *card)
+	}
+	return cards, nil
+}
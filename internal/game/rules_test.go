@@ -0,0 +1,109 @@
+package game
+
+import "testing"
+
+func TestReplaceHandKeepsExtraDraw(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace func(g *Game, playerID int) error
+	}{
+		{"reboot", (*Game).RebootUniverse},
+		{"mulligan", (*Game).Mulligan},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, 3, 1)
+			for i := range g.Decks[0].QuestionCards {
+				g.Decks[0].QuestionCards[i].Draw = 2
+			}
+			g.Settings.HouseRules = HouseRules{RebootUniverse: true, Mulligan: true}
+			if err := g.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			var player *Player
+			for _, p := range g.Players {
+				if p != g.Round.Czar {
+					player = p
+					break
+				}
+			}
+			player.Score = 1
+
+			want := g.Settings.HandSize + 2
+			if len(player.Hand) != want {
+				t.Fatalf("dealt %d cards, want %d", len(player.Hand), want)
+			}
+
+			if err := tt.replace(g, player.ID); err != nil {
+				t.Fatal(err)
+			}
+			if len(player.Hand) != want {
+				t.Errorf("replaced hand has %d cards, want %d", len(player.Hand), want)
+			}
+		})
+	}
+}
+
+func TestRandoPassesOverBlanks(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers int
+		submits bool
+	}{
+		{"enough answers", 2, true},
+		{"too few answers", 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, 3, 1)
+			for i := range g.Decks[0].QuestionCards {
+				g.Decks[0].QuestionCards[i].NumAnswers = 2
+			}
+			g.Settings.HouseRules = HouseRules{Rando: true}
+			if err := g.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			// Replace Rando's submission with one drawn from
+			// a deck of blanks with a few answers among them.
+			g.Round.CardSubmissions = g.Round.CardSubmissions[:0]
+			deck := []AnswerCard{}
+			for i := 1; i <= MaxBlankCards; i++ {
+				deck = append(deck, AnswerCard{ID: i, Blank: true})
+			}
+			for i, at := range []int{10, MaxBlankCards - 10}[:tt.answers] {
+				deck[at] = AnswerCard{ID: 1000 + i, DeckID: 1}
+			}
+			g.PlayDeck.AnswerDeck.Init(deck)
+
+			g.submitBots()
+
+			submission := g.Round.Submission(RandoID)
+			if !tt.submits {
+				if submission != nil {
+					t.Fatalf("Rando submitted %+v", submission.Cards)
+				}
+				if n := len(g.PlayDeck.AnswerDeck.Deck) + len(g.PlayDeck.AnswerDeck.DiscardPile); n != len(deck) {
+					t.Errorf("%d cards left in the deck, want %d", n, len(deck))
+				}
+			} else {
+				if submission == nil || len(submission.Cards) != 2 {
+					t.Fatalf("Rando submitted %v, want 2 cards", submission)
+				}
+				for _, card := range submission.Cards {
+					if card.Blank {
+						t.Errorf("Rando played a blank card")
+					}
+				}
+			}
+
+			submitAll(t, g)
+			if g.Phase != WinnerSelection {
+				t.Errorf("in %v once everyone else submitted, want winner selection", g.Phase)
+			}
+		})
+	}
+}
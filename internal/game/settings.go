@@ -0,0 +1,217 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// MinHandSize is the fewest cards a game may deal each
+	// player.
+	MinHandSize int = 5
+
+	// MaxHandSize is the most cards a game may deal each
+	// player.
+	MaxHandSize int = 20
+
+	// MinMaxPoints is the fewest points a game may be played to.
+	MinMaxPoints int = 3
+
+	// MaxMaxPoints is the most points a game may be played to.
+	MaxMaxPoints int = 10
+
+	// DefaultMaxPlayers is the default number of people who
+	// may join a game.
+	DefaultMaxPlayers int = 10
+
+	// MaxMaxPlayers is the most people who may join a game.
+	MaxMaxPlayers int = 20
+
+	// MinPhaseSeconds is the shortest time limit which may be
+	// put on a phase of a round.
+	MinPhaseSeconds int = 10
+
+	// MaxPhaseSeconds is the longest time limit which may be
+	// put on a phase of a round.
+	MaxPhaseSeconds int = 600
+)
+
+// Visibility determines who can find a game.
+type Visibility string
+
+const (
+	// Public games are listed for everyone.
+	Public Visibility = "public"
+
+	// Unlisted games are left out of games lists, so may only
+	// be joined by those who know their ID.
+	Unlisted Visibility = "unlisted"
+)
+
+// GameSettings are the options chosen for a game in its
+// lobby.
+type GameSettings struct {
+	// HandSize is the number of cards kept in each hand.
+	HandSize int `json:"handSize"`
+
+	// MaxPoints is the number of points required to win.
+	MaxPoints int `json:"maxPoints"`
+
+	// MaxPlayers is the most people who may join.
+	MaxPlayers int `json:"maxPlayers"`
+
+	// SubmitSeconds limits the time for submitting cards,
+	// and JudgeSeconds the time for choosing a winner. Zero
+	// means no limit.
+	SubmitSeconds int `json:"submitSeconds"`
+	JudgeSeconds  int `json:"judgeSeconds"`
+
+	// DeckIDs are the IDs of the library decks to play with.
+	DeckIDs []int `json:"deckIds"`
+
+	// TagFilter selects the cards put into play from the
+	// game's decks.
+	TagFilter TagFilter `json:"tagFilter"`
+
+	// NumBlanks is the number of blank cards added to the
+	// answer pool.
+	NumBlanks int `json:"numBlanks"`
+
+	// HouseRules are the optional rules in play.
+	HouseRules HouseRules `json:"houseRules"`
+
+	// Mode decides how the winner of each round is chosen.
+	Mode RuleMode `json:"mode"`
+
+	// EliminateEvery is the number of rounds between
+	// eliminations in survival mode, or 0 outside it.
+	EliminateEvery int `json:"eliminateEvery"`
+
+	// Visibility determines who can find the game.
+	Visibility Visibility `json:"visibility"`
+}
+
+// DefaultSettings returns the settings new games start with.
+func DefaultSettings() GameSettings {
+	return GameSettings{
+		HandSize:   DefaultHandSize,
+		MaxPoints:  DefaultMaxPoints,
+		MaxPlayers: DefaultMaxPlayers,
+		DeckIDs:    []int{},
+		Visibility: Public,
+	}
+}
+
+// Validate checks that each setting is within its limits.
+func (s GameSettings) Validate() error {
+	if s.HandSize < MinHandSize || s.HandSize > MaxHandSize {
+		return fmt.Errorf("hand size must be between %d and %d", MinHandSize, MaxHandSize)
+	}
+
+	if s.MaxPoints < MinMaxPoints || s.MaxPoints > MaxMaxPoints {
+		return fmt.Errorf("max points must be between %d and %d", MinMaxPoints, MaxMaxPoints)
+	}
+
+	if s.MaxPlayers < MinPlayers || s.MaxPlayers > MaxMaxPlayers {
+		return fmt.Errorf("max players must be between %d and %d", MinPlayers, MaxMaxPlayers)
+	}
+
+	for _, seconds := range []int{s.SubmitSeconds, s.JudgeSeconds} {
+		if seconds != 0 && (seconds < MinPhaseSeconds || seconds > MaxPhaseSeconds) {
+			return fmt.Errorf("time limits must be 0 or between %d and %d seconds", MinPhaseSeconds, MaxPhaseSeconds)
+		}
+	}
+
+	if s.NumBlanks < 0 || s.NumBlanks > MaxBlankCards {
+		return fmt.Errorf("number of blank cards must be between 0 and %d", MaxBlankCards)
+	}
+
+	if int(s.Mode) < 0 || int(s.Mode) >= len(ruleModeNames) {
+		return errors.New("invalid rule mode")
+	}
+
+	if s.EliminateEvery < 0 || s.EliminateEvery > MaxEliminateEvery {
+		return fmt.Errorf("rounds between eliminations must be between 0 and %d", MaxEliminateEvery)
+	}
+
+	if s.Visibility != Public && s.Visibility != Unlisted {
+		return fmt.Errorf("invalid visibility %q", s.Visibility)
+	}
+	return nil
+}
+
+// Copy returns a copy of the settings which shares no
+// storage with the original.
+func (s GameSettings) Copy() GameSettings {
+	s.DeckIDs = append([]int{}, s.DeckIDs...)
+	s.TagFilter = TagFilter{
+		Include: copyStrings(s.TagFilter.Include),
+		Exclude: copyStrings(s.TagFilter.Exclude),
+	}
+	return s
+}
+
+// copyStrings copies a list of strings, keeping nil as nil.
+func copyStrings(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	return append([]string{}, list...)
+}
+
+// withDefaults fills in settings missing from games saved
+// before they existed.
+func (s GameSettings) withDefaults() GameSettings {
+	defaults := DefaultSettings()
+	if s.HandSize == 0 {
+		s.HandSize = defaults.HandSize
+	}
+	if s.MaxPoints == 0 {
+		s.MaxPoints = defaults.MaxPoints
+	}
+	if s.MaxPlayers == 0 {
+		s.MaxPlayers = defaults.MaxPlayers
+	}
+	if s.DeckIDs == nil {
+		s.DeckIDs = defaults.DeckIDs
+	}
+	if s.Visibility == "" {
+		s.Visibility = defaults.Visibility
+	}
+	return s
+}
+
+// UpdateSettings replaces the settings of the game.
+//
+// Will fail if the game is outside the lobby phase, if any
+// setting is invalid or if more players have joined than
+// the new settings allow.
+func (g *Game) UpdateSettings(settings GameSettings) (err error) {
+	if g.Phase != Lobby {
+		return errors.New("can't change settings outside lobby phase")
+	}
+
+	settings.TagFilter = settings.TagFilter.Normalise()
+	err = settings.Validate()
+	if err != nil {
+		return err
+	}
+
+	if len(g.Players) > settings.MaxPlayers {
+		return fmt.Errorf("%d players have already joined", len(g.Players))
+	}
+
+	g.Settings = settings
+	return
+}
+
+// SetMaxPoints changes the number of points required to win
+// the game.
+//
+// Will fail if the game is outside the lobby phase or if
+// the value isn't between `MinMaxPoints` and `MaxMaxPoints`.
+func (g *Game) SetMaxPoints(maxPoints int) (err error) {
+	settings := g.Settings.Copy()
+	settings.MaxPoints = maxPoints
+	return g.UpdateSettings(settings)
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUpdateSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *GameSettings)
+		valid  bool
+	}{
+		{"defaults", func(s *GameSettings) {}, true},
+		{"hand size", func(s *GameSettings) { s.HandSize = MaxHandSize }, true},
+		{"hand size too small", func(s *GameSettings) { s.HandSize = MinHandSize - 1 }, false},
+		{"hand size too large", func(s *GameSettings) { s.HandSize = MaxHandSize + 1 }, false},
+		{"max points too small", func(s *GameSettings) { s.MaxPoints = MinMaxPoints - 1 }, false},
+		{"max points too large", func(s *GameSettings) { s.MaxPoints = MaxMaxPoints + 1 }, false},
+		{"fewer max players than joined", func(s *GameSettings) { s.MaxPlayers = MinPlayers }, false},
+		{"too many max players", func(s *GameSettings) { s.MaxPlayers = MaxMaxPlayers + 1 }, false},
+		{"time limits", func(s *GameSettings) { s.SubmitSeconds, s.JudgeSeconds = MinPhaseSeconds, MaxPhaseSeconds }, true},
+		{"time limit too short", func(s *GameSettings) { s.SubmitSeconds = MinPhaseSeconds - 1 }, false},
+		{"time limit too long", func(s *GameSettings) { s.JudgeSeconds = MaxPhaseSeconds + 1 }, false},
+		{"too many blanks", func(s *GameSettings) { s.NumBlanks = MaxBlankCards + 1 }, false},
+		{"house rules", func(s *GameSettings) { s.HouseRules = HouseRules{Rando: true, Mulligan: true} }, true},
+		{"god is dead", func(s *GameSettings) { s.Mode = GodIsDead }, true},
+		{"unknown mode", func(s *GameSettings) { s.Mode = RuleMode(-1) }, false},
+		{"survival", func(s *GameSettings) { s.EliminateEvery = 2 }, true},
+		{"negative survival", func(s *GameSettings) { s.EliminateEvery = -1 }, false},
+		{"unlisted", func(s *GameSettings) { s.Visibility = Unlisted }, true},
+		{"unknown visibility", func(s *GameSettings) { s.Visibility = "secret" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, 4, 1)
+			before := g.Settings
+
+			settings := g.Settings
+			tt.change(&settings)
+			err := g.UpdateSettings(settings)
+			if (err == nil) != tt.valid {
+				t.Fatalf("UpdateSettings(%+v) = %v, want valid %v", settings, err, tt.valid)
+			}
+
+			want := before
+			if tt.valid {
+				want = settings
+			}
+			if !reflect.DeepEqual(g.Settings, want) {
+				t.Errorf("settings are %+v, want %+v", g.Settings, want)
+			}
+		})
+	}
+}
+
+func TestUpdateSettingsNormalisesTagFilter(t *testing.T) {
+	g := newTestGame(t, 3, 1)
+
+	settings := g.Settings
+	settings.TagFilter = TagFilter{Exclude: []string{" NSFW ", "nsfw", ""}}
+	if err := g.UpdateSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"nsfw"}; !reflect.DeepEqual(g.Settings.TagFilter.Exclude, want) {
+		t.Errorf("excluded tags are %q, want %q", g.Settings.TagFilter.Exclude, want)
+	}
+}
+
+func TestUpdateSettingsOnlyInLobby(t *testing.T) {
+	g := newTestGame(t, 3, 1)
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := g.Settings
+	settings.MaxPoints = MaxMaxPoints
+	if err := g.UpdateSettings(settings); err == nil {
+		t.Error("changed settings after game started")
+	}
+	if err := g.SetMaxPoints(MaxMaxPoints); err == nil {
+		t.Error("changed max points after game started")
+	}
+	if g.Settings.MaxPoints == MaxMaxPoints {
+		t.Error("max points changed")
+	}
+}
@@ -0,0 +1,381 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format
+// produced by `Snapshot`.
+//
+// It must be incremented whenever the format changes in a
+// way older code could not read.
+const SnapshotVersion int = 1
+
+// Snapshot is a serialisable copy of a game's state.
+//
+// References between players are replaced by player IDs,
+// with 0 standing in for no player. Settings are stored
+// inline, as they were before being gathered together, so
+// that older snapshots still load.
+type Snapshot struct {
+	Version  int              `json:"version"`
+	ID       int              `json:"id"`
+	Decks    []Deck           `json:"decks"`
+	Phase    Phase            `json:"phase"`
+	Name     string           `json:"name"`
+	Owner    int              `json:"owner"`
+	Password string           `json:"password"`
+	PlayDeck PlayDeckSnapshot `json:"playDeck"`
+	Players  []PlayerSnapshot `json:"players"`
+	Round    *RoundSnapshot   `json:"round,omitempty"`
+	History  []RoundResult    `json:"history,omitempty"`
+	Seed     int64            `json:"seed"`
+
+	// Deadline is when the current phase times out, if it
+	// has a time limit.
+	Deadline *time.Time `json:"deadline,omitempty"`
+
+	GameSettings
+
+	// RandomCalls is the number of values drawn from the
+	// game's source of randomness since it was seeded.
+	RandomCalls uint64 `json:"randomCalls"`
+}
+
+// PlayDeckSnapshot is a serialisable copy of a `PlayDeck`.
+type PlayDeckSnapshot struct {
+	AnswerDeck          []AnswerCard   `json:"answerDeck"`
+	AnswerDiscardPile   []AnswerCard   `json:"answerDiscardPile"`
+	QuestionDeck        []QuestionCard `json:"questionDeck"`
+	QuestionDiscardPile []QuestionCard `json:"questionDiscardPile"`
+}
+
+// PlayerSnapshot is a serialisable copy of a `Player`.
+type PlayerSnapshot struct {
+	ID         int          `json:"id"`
+	Username   string       `json:"username"`
+	Hand       []AnswerCard `json:"hand"`
+	Score      int          `json:"score"`
+	Bot        bool         `json:"bot,omitempty"`
+	Mulliganed bool         `json:"mulliganed,omitempty"`
+	Eliminated bool         `json:"eliminated,omitempty"`
+}
+
+// RoundSnapshot is a serialisable copy of a `Round`.
+type RoundSnapshot struct {
+	Number          int                  `json:"number"`
+	CardSubmissions []SubmissionSnapshot `json:"cardSubmissions"`
+	Czar            int                  `json:"czar"`
+	Question        *QuestionCard        `json:"question,omitempty"`
+	Votes           []VoteSnapshot       `json:"votes,omitempty"`
+	Winner          int                  `json:"winner"`
+}
+
+// VoteSnapshot is a serialisable copy of a `Vote`.
+type VoteSnapshot struct {
+	Voter  int `json:"voter"`
+	Player int `json:"player"`
+}
+
+// SubmissionSnapshot is a serialisable copy of a
+// `CardSubmission`.
+type SubmissionSnapshot struct {
+	ID     string       `json:"id"`
+	Cards  []AnswerCard `json:"cards"`
+	Player int          `json:"player"`
+}
+
+// Snapshot copies the state of the game into a form which
+// may be serialised.
+func (g *Game) Snapshot() (s *Snapshot, err error) {
+	s = &Snapshot{
+		Version:      SnapshotVersion,
+		ID:           g.ID,
+		Decks:        make([]Deck, 0, len(g.Decks)),
+		Phase:        g.Phase,
+		Name:         g.Name,
+		Owner:        playerID(g.Owner),
+		Password:     g.Password,
+		Players:      make([]PlayerSnapshot, 0, len(g.Players)),
+		History:      copyHistory(g.History),
+		Seed:         g.Seed,
+		GameSettings: g.Settings.Copy(),
+	}
+
+	if g.random != nil {
+		s.RandomCalls = g.random.Calls()
+	}
+
+	if !g.Deadline.IsZero() {
+		deadline := g.Deadline
+		s.Deadline = &deadline
+	}
+
+	for _, deck := range g.Decks {
+		s.Decks = append(s.Decks, copyDeck(*deck))
+	}
+
+	s.PlayDeck = PlayDeckSnapshot{
+		AnswerDeck:          copyCards(g.PlayDeck.AnswerDeck.Deck),
+		AnswerDiscardPile:   copyCards(g.PlayDeck.AnswerDeck.DiscardPile),
+		QuestionDeck:        copyCards(g.PlayDeck.QuestionDeck.Deck),
+		QuestionDiscardPile: copyCards(g.PlayDeck.QuestionDeck.DiscardPile),
+	}
+
+	for _, player := range g.Players {
+		ps := PlayerSnapshot{
+			ID:         player.ID,
+			Username:   player.Username,
+			Hand:       make([]AnswerCard, 0, len(player.Hand)),
+			Score:      player.Score,
+			Bot:        player.Bot,
+			Mulliganed: player.Mulliganed,
+			Eliminated: player.Eliminated,
+		}
+		for _, card := range player.Hand {
+			ps.Hand = append(ps.Hand, *card)
+		}
+		s.Players = append(s.Players, ps)
+	}
+
+	if g.Round != nil {
+		s.Round = &RoundSnapshot{
+			Number:          g.Round.Number,
+			CardSubmissions: make([]SubmissionSnapshot, 0, len(g.Round.CardSubmissions)),
+			Czar:            playerID(g.Round.Czar),
+			Question:        copyQuestion(g.Round.Question),
+			Winner:          playerID(g.Round.Winner),
+		}
+		for _, submission := range g.Round.CardSubmissions {
+			s.Round.CardSubmissions = append(s.Round.CardSubmissions, SubmissionSnapshot{
+				ID:     submission.ID,
+				Cards:  copyCards(submission.Cards),
+				Player: playerID(submission.Player),
+			})
+		}
+		for _, vote := range g.Round.Votes {
+			s.Round.Votes = append(s.Round.Votes, VoteSnapshot{
+				Voter:  playerID(vote.Voter),
+				Player: playerID(vote.Player),
+			})
+		}
+	}
+
+	return s, nil
+}
+
+// Restore rebuilds a game from a snapshot.
+//
+// Fails if the snapshot was written by a newer version or
+// refers to players who aren't part of the game.
+func Restore(s *Snapshot) (g *Game, err error) {
+	if s == nil {
+		return nil, errors.New("must specify a snapshot")
+	}
+
+	if s.Version < 1 || s.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", s.Version)
+	}
+
+	g = &Game{
+		ID:       s.ID,
+		Decks:    make([]*Deck, 0, len(s.Decks)),
+		Phase:    s.Phase,
+		Name:     s.Name,
+		Password: s.Password,
+		Players:  make([]*Player, 0, len(s.Players)),
+		Settings: s.GameSettings.withDefaults().Copy(),
+		History:  copyHistory(s.History),
+		Seed:     s.Seed,
+	}
+
+	if s.Deadline != nil {
+		g.Deadline = *s.Deadline
+	}
+
+	if s.Phase != Lobby {
+		g.random = restoreRandom(s.Seed, s.RandomCalls)
+		g.PlayDeck.random = g.random
+	}
+
+	for i := range s.Decks {
+		deck := copyDeck(s.Decks[i])
+		g.Decks = append(g.Decks, &deck)
+	}
+
+	g.PlayDeck.AnswerDeck.Deck = copyCards(s.PlayDeck.AnswerDeck)
+	g.PlayDeck.AnswerDeck.DiscardPile = copyCards(s.PlayDeck.AnswerDiscardPile)
+	g.PlayDeck.QuestionDeck.Deck = copyCards(s.PlayDeck.QuestionDeck)
+	g.PlayDeck.QuestionDeck.DiscardPile = copyCards(s.PlayDeck.QuestionDiscardPile)
+
+	for _, ps := range s.Players {
+		player := &Player{
+			ID:         ps.ID,
+			Username:   ps.Username,
+			Hand:       make([]*AnswerCard, 0, len(ps.Hand)),
+			Score:      ps.Score,
+			Bot:        ps.Bot,
+			Mulliganed: ps.Mulliganed,
+			Eliminated: ps.Eliminated,
+		}
+		for i := range ps.Hand {
+			card := ps.Hand[i]
+			player.Hand = append(player.Hand, &card)
+		}
+		g.Players = append(g.Players, player)
+	}
+
+	g.Owner, err = g.snapshotPlayer(s.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Round == nil {
+		return g, nil
+	}
+
+	g.Round = &Round{
+		Number:          s.Round.Number,
+		CardSubmissions: make([]CardSubmission, 0, len(s.Round.CardSubmissions)),
+		Question:        copyQuestion(s.Round.Question),
+	}
+
+	g.Round.Czar, err = g.snapshotPlayer(s.Round.Czar)
+	if err != nil {
+		return nil, err
+	}
+
+	g.Round.Winner, err = g.snapshotPlayer(s.Round.Winner)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ss := range s.Round.CardSubmissions {
+		player, err := g.snapshotPlayer(ss.Player)
+		if err != nil {
+			return nil, err
+		}
+		if player == nil {
+			return nil, errors.New("snapshot submission has no player")
+		}
+		g.Round.CardSubmissions = append(g.Round.CardSubmissions, CardSubmission{
+			ID:     ss.ID,
+			Cards:  copyCards(ss.Cards),
+			Player: player,
+		})
+	}
+
+	// Submissions saved before they were given IDs are given
+	// them now, so that they can still be told apart. They are
+	// drawn from outside the game's own randomness, which would
+	// otherwise no longer match the saved number of draws.
+	for i := range g.Round.CardSubmissions {
+		if g.Round.CardSubmissions[i].ID == "" {
+			g.Round.CardSubmissions[i].ID = g.newSubmissionID(secureUint64)
+		}
+	}
+
+	for _, vs := range s.Round.Votes {
+		voter, err := g.snapshotPlayer(vs.Voter)
+		if err != nil {
+			return nil, err
+		}
+		player, err := g.snapshotPlayer(vs.Player)
+		if err != nil {
+			return nil, err
+		}
+		if voter == nil || player == nil {
+			return nil, errors.New("snapshot vote is missing a player")
+		}
+		g.Round.Votes = append(g.Round.Votes, Vote{
+			Voter:  voter,
+			Player: player,
+		})
+	}
+
+	return g, nil
+}
+
+// MarshalSnapshot serialises a snapshot of the game as JSON.
+func MarshalSnapshot(g *Game) (data []byte, err error) {
+	s, err := g.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(s)
+}
+
+// UnmarshalSnapshot restores a game from a JSON snapshot.
+func UnmarshalSnapshot(data []byte) (g *Game, err error) {
+	s := &Snapshot{}
+	err = json.Unmarshal(data, s)
+	if err != nil {
+		return nil, err
+	}
+	return Restore(s)
+}
+
+// snapshotPlayer resolves a player ID from a snapshot,
+// where 0 stands for no player.
+func (g *Game) snapshotPlayer(id int) (*Player, error) {
+	if id == 0 {
+		return nil, nil
+	}
+
+	player := g.Player(id)
+	if player == nil {
+		return nil, fmt.Errorf("snapshot refers to unknown player %d", id)
+	}
+	return player, nil
+}
+
+// playerID returns the ID of a player, or 0 if nil.
+func playerID(player *Player) int {
+	if player == nil {
+		return 0
+	}
+	return player.ID
+}
+
+// copyCards copies a pile of cards, so that a snapshot
+// doesn't share storage with a live game.
+//
+// Cards are never changed once loaded, so their tag lists
+// are shared rather than copied.
+func copyCards[T any](cards []T) []T {
+	return append(make([]T, 0, len(cards)), cards...)
+}
+
+// copyDeck copies a deck and its cards.
+func copyDeck(deck Deck) Deck {
+	deck.QuestionCards = copyCards(deck.QuestionCards)
+	deck.AnswerCards = copyCards(deck.AnswerCards)
+	deck.Tags = copyStrings(deck.Tags)
+	return deck
+}
+
+// copyQuestion copies a question card, keeping nil as nil.
+func copyQuestion(card *QuestionCard) *QuestionCard {
+	if card == nil {
+		return nil
+	}
+	copied := *card
+	return &copied
+}
+
+// copyHistory copies the results of past rounds, along with
+// the cards played in them.
+func copyHistory(history []RoundResult) []RoundResult {
+	copied := make([]RoundResult, 0, len(history))
+	for _, result := range history {
+		result.Cards = copyCards(result.Cards)
+		if result.Blanks != nil {
+			result.Blanks = copyCards(result.Blanks)
+		}
+		copied = append(copied, result)
+	}
+	return copied
+}
@@ -0,0 +1,175 @@
+package game
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// submitAll has every player other than the Czar who has yet
+// to submit play the first cards in their hand.
+func submitAll(t *testing.T, g *Game) {
+	t.Helper()
+
+	for _, player := range g.Players {
+		if player == g.Round.Czar || player.Bot || !player.active() || g.Round.Submission(player.ID) != nil {
+			continue
+		}
+
+		cards := []AnswerCard{}
+		for _, card := range player.Hand[:g.Round.Question.Pick()] {
+			cards = append(cards, *card)
+		}
+		if err := g.SubmitCards(player.ID, cards); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRestoreBackfillsSubmissionIDs(t *testing.T) {
+	g := newTestGame(t, 4, 1)
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+	submitAll(t, g)
+
+	s, err := g.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range s.Round.CardSubmissions {
+		s.Round.CardSubmissions[i].ID = ""
+	}
+
+	restored, err := Restore(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+	for _, submission := range restored.Round.CardSubmissions {
+		if submission.ID == "" || seen[submission.ID] {
+			t.Fatalf("submission ID %q is empty or repeated", submission.ID)
+		}
+		seen[submission.ID] = true
+
+		if restored.Round.SubmissionByID(submission.ID).Player != submission.Player {
+			t.Errorf("submission %q found the wrong player", submission.ID)
+		}
+	}
+
+	if restored.Round.SubmissionByID("") != nil {
+		t.Error("empty submission ID matched a submission")
+	}
+	if calls := restored.random.Calls(); calls != s.RandomCalls {
+		t.Errorf("restoring drew %d random values, want %d", calls, s.RandomCalls)
+	}
+}
+
+// votingGame starts a timed game without a Czar, in which
+// everyone has submitted and one vote has been cast.
+func votingGame(t *testing.T) *Game {
+	t.Helper()
+
+	g, _ := newTimedGame(t, GodIsDead, 3)
+	g.Settings.TagFilter = TagFilter{Exclude: []string{"nsfw"}}
+	g.Settings.DeckIDs = []int{1}
+	submitAll(t, g)
+	if g.Phase != Voting {
+		t.Fatalf("in %v, want voting", g.Phase)
+	}
+
+	target := g.Round.Submission(g.Players[1].ID)
+	if err := g.CastVote(g.Players[0].ID, target.ID); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	g := votingGame(t)
+
+	data, err := MarshalSnapshot(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := UnmarshalSnapshot(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := MarshalSnapshot(restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Fatalf("snapshot changed on round trip:\n%s\n%s", data, again)
+	}
+
+	if restored.Phase != Voting || !restored.Deadline.Equal(g.Deadline) {
+		t.Errorf("restored in %v with deadline %v", restored.Phase, restored.Deadline)
+	}
+	if restored.random.Calls() != g.random.Calls() {
+		t.Errorf("restored after %d random calls, want %d", restored.random.Calls(), g.random.Calls())
+	}
+	if len(restored.Round.Votes) != 1 || restored.Round.Votes[0].Voter != restored.Player(g.Players[0].ID) {
+		t.Errorf("votes restored as %+v", restored.Round.Votes)
+	}
+	for _, player := range g.Players {
+		if got, want := restored.ViewFor(player.ID), g.ViewFor(player.ID); !reflect.DeepEqual(got, want) {
+			t.Errorf("player %d sees %+v, want %+v", player.ID, got, want)
+		}
+	}
+
+	// Both games should go on to the same result.
+	for _, game := range []*Game{g, restored} {
+		for _, player := range game.Players[1:] {
+			target := game.Round.Submission(game.Players[0].ID)
+			if err := game.CastVote(player.ID, target.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if !reflect.DeepEqual(restored.History, g.History) {
+		t.Errorf("restored game ended round as %+v, want %+v", restored.History, g.History)
+	}
+}
+
+func TestSnapshotSharesNothing(t *testing.T) {
+	g := votingGame(t)
+	before, err := MarshalSnapshot(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := g.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.GameSettings.TagFilter.Exclude[0] = "changed"
+	s.GameSettings.DeckIDs[0] = 99
+	s.Round.Question.Text = "changed"
+	s.Round.CardSubmissions[0].Cards[0].Text = "changed"
+	s.Decks[0].AnswerCards[0].Text = "changed"
+	s.PlayDeck.AnswerDeck[0].Text = "changed"
+
+	if after, _ := MarshalSnapshot(g); string(after) != string(before) {
+		t.Error("changing the snapshot changed the game")
+	}
+
+	s, _ = g.Snapshot()
+	snapshotJSON, _ := json.Marshal(s)
+	restored, err := Restore(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored.Settings.TagFilter.Exclude[0] = "changed"
+	restored.Settings.DeckIDs[0] = 99
+	restored.Round.Question.Text = "changed"
+	restored.Round.CardSubmissions[0].Cards[0].Text = "changed"
+	restored.Decks[0].AnswerCards[0].Text = "changed"
+
+	if after, _ := json.Marshal(s); string(after) != string(snapshotJSON) {
+		t.Error("changing the restored game changed the snapshot")
+	}
+}
@@ -0,0 +1,93 @@
+package game
+
+// MaxEliminateEvery is the most rounds which may be played
+// between eliminations in survival mode.
+const MaxEliminateEvery int = 20
+
+// Survival reports whether the game is in survival mode.
+func (g *Game) Survival() bool {
+	return g.Settings.EliminateEvery > 0
+}
+
+// active reports whether a player is still in play. Bots
+// always are, while people may be eliminated.
+func (p *Player) active() bool {
+	return !p.Eliminated
+}
+
+// activeHumans lists the people who have yet to be
+// eliminated.
+func (g *Game) activeHumans() (players []*Player) {
+	for _, player := range g.Players {
+		if !player.Bot && player.active() {
+			players = append(players, player)
+		}
+	}
+	return
+}
+
+// tooFewPlayers reports whether too few people remain for
+// play to go on.
+func (g *Game) tooFewPlayers() bool {
+	if g.Survival() {
+		return len(g.activeHumans()) < 2
+	}
+	return g.NumHumans() < MinPlayers
+}
+
+// lastElimination finds the number of the round in which a
+// player was last eliminated, or 0 if nobody has been.
+func (g *Game) lastElimination() int {
+	for i := len(g.History) - 1; i >= 0; i-- {
+		if g.History[i].Eliminated != 0 {
+			return g.History[i].Number
+		}
+	}
+	return 0
+}
+
+// eliminate removes the lowest scoring person from play once
+// enough rounds have passed since the last elimination,
+// ending the game once only one is left.
+//
+// Rounds skipped without a winner still count, so the
+// elimination they would have brought happens at the end of
+// the next round which has one.
+//
+// Ties are broken against whoever is seated last. Returns
+// the eliminated player, if any.
+func (g *Game) eliminate() *Player {
+	if !g.Survival() || g.Round.Number-g.lastElimination() < g.Settings.EliminateEvery {
+		return nil
+	}
+
+	remaining := g.activeHumans()
+	if len(remaining) < 2 {
+		return nil
+	}
+
+	loser := remaining[0]
+	for _, player := range remaining[1:] {
+		if player.Score <= loser.Score {
+			loser = player
+		}
+	}
+
+	loser.Eliminated = true
+	for _, card := range loser.Hand {
+		g.PlayDeck.DiscardAnswer(*card)
+	}
+	loser.Hand = nil
+	return loser
+}
+
+// survivor retrieves the last person left in play.
+//
+// Returns nil if more than one remains.
+func (g *Game) survivor() *Player {
+	remaining := g.activeHumans()
+	if len(remaining) != 1 {
+		return nil
+	}
+	return remaining[0]
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+// newSurvivalGame starts a timed survival game with four
+// players, eliminating one every `every` rounds.
+func newSurvivalGame(t *testing.T, every int) (*Game, *fakeClock) {
+	t.Helper()
+
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)}
+	g := newTestGame(t, 4, 1)
+	g.SetClock(clock)
+	g.Settings.SubmitSeconds = testSubmitSeconds
+	g.Settings.EliminateEvery = every
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return g, clock
+}
+
+// skipRound times out the current round with nothing
+// submitted, so it ends without a winner.
+//
+// Hands are blanked so nothing can be played for anyone,
+// then given back once the round is over.
+func skipRound(t *testing.T, g *Game, clock *fakeClock) {
+	t.Helper()
+
+	hands := map[*Player][]*AnswerCard{}
+	for _, player := range g.Players {
+		if player != g.Round.Czar {
+			hands[player] = append([]*AnswerCard(nil), player.Hand...)
+			blankHand(player)
+		}
+	}
+	clock.advance(time.Hour)
+	if err := g.Expire(); err != nil {
+		t.Fatal(err)
+	}
+	for player, hand := range hands {
+		player.Hand = hand
+	}
+}
+
+// playToWinner plays the current round through to a winner,
+// returning the player eliminated at its end, if any.
+func playToWinner(t *testing.T, g *Game) int {
+	t.Helper()
+
+	submitAll(t, g)
+	czarPick(t, g)
+	eliminated := g.LastResult().Eliminated
+	if g.Phase == EndOfRound {
+		if err := g.NextRound(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return eliminated
+}
+
+func TestEliminateEvery(t *testing.T) {
+	g, _ := newSurvivalGame(t, 2)
+
+	if eliminated := playToWinner(t, g); eliminated != 0 {
+		t.Errorf("player %d eliminated after round 1", eliminated)
+	}
+	eliminated := playToWinner(t, g)
+	if eliminated == 0 {
+		t.Fatal("nobody eliminated after round 2")
+	}
+	if player := g.Player(eliminated); !player.Eliminated || len(player.Hand) != 0 {
+		t.Errorf("eliminated player %d is still in play", eliminated)
+	}
+	if eliminated := playToWinner(t, g); eliminated != 0 {
+		t.Errorf("player %d eliminated after round 3", eliminated)
+	}
+	if eliminated := playToWinner(t, g); eliminated == 0 {
+		t.Error("nobody eliminated after round 4")
+	}
+}
+
+func TestSkippedRoundsCountTowardsElimination(t *testing.T) {
+	g, clock := newSurvivalGame(t, 2)
+
+	if eliminated := playToWinner(t, g); eliminated != 0 {
+		t.Errorf("player %d eliminated after round 1", eliminated)
+	}
+	skipRound(t, g, clock)
+	if g.Round.Number != 3 || len(g.History) != 1 {
+		t.Fatalf("in round %d with %d results, want round 3 after 1 result", g.Round.Number, len(g.History))
+	}
+
+	if eliminated := playToWinner(t, g); eliminated == 0 {
+		t.Error("nobody eliminated after skipped round")
+	}
+	if eliminated := playToWinner(t, g); eliminated != 0 {
+		t.Errorf("player %d eliminated one round after the last", eliminated)
+	}
+}
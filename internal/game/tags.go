@@ -0,0 +1,69 @@
+package game
+
+import "strings"
+
+// TagFilter selects cards by their tags.
+//
+// A card is kept if it has at least one of the included tags,
+// or if no tags are included, and none of the excluded tags.
+// Tags are compared without regard to case.
+type TagFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Allows reports whether a card with the given tags passes
+// the filter.
+func (f TagFilter) Allows(tags ...[]string) bool {
+	included := len(f.Include) < 1
+	for _, group := range tags {
+		for _, tag := range group {
+			tag = normaliseTag(tag)
+			if hasTag(f.Exclude, tag) {
+				return false
+			}
+			if hasTag(f.Include, tag) {
+				included = true
+			}
+		}
+	}
+	return included
+}
+
+// Normalise lower-cases the tags of the filter, dropping
+// blank and repeated ones.
+func (f TagFilter) Normalise() TagFilter {
+	return TagFilter{
+		Include: NormaliseTags(f.Include),
+		Exclude: NormaliseTags(f.Exclude),
+	}
+}
+
+// NormaliseTags lower-cases and trims a list of tags,
+// dropping blank and repeated ones.
+func NormaliseTags(tags []string) (normalised []string) {
+	for _, tag := range tags {
+		tag = normaliseTag(tag)
+		if tag == "" || hasTag(normalised, tag) {
+			continue
+		}
+		normalised = append(normalised, tag)
+	}
+	return
+}
+
+// normaliseTag puts a tag into the form used for comparison.
+func normaliseTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// hasTag reports whether a normalised tag is in a list of
+// normalised tags.
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
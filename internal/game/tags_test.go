@@ -0,0 +1,102 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTagFilterAllows(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    TagFilter
+		deckTags  []string
+		cardTags  []string
+		wantAllow bool
+	}{
+		{"no filter", TagFilter{}, []string{"nsfw"}, nil, true},
+		{"untagged with exclusions", TagFilter{Exclude: []string{"nsfw"}}, nil, nil, true},
+		{"excluded card tag", TagFilter{Exclude: []string{"nsfw"}}, nil, []string{"nsfw"}, false},
+		{"excluded deck tag", TagFilter{Exclude: []string{"nsfw"}}, []string{"nsfw"}, []string{"kids"}, false},
+		{"tags compared without case", TagFilter{Exclude: []string{"NSFW "}}, nil, []string{" Nsfw"}, false},
+		{"included card tag", TagFilter{Include: []string{"kids"}}, nil, []string{"kids"}, true},
+		{"included deck tag", TagFilter{Include: []string{"kids"}}, []string{"Kids"}, nil, true},
+		{"missing included tag", TagFilter{Include: []string{"kids"}}, []string{"base"}, []string{"pop"}, false},
+		{"untagged with inclusions", TagFilter{Include: []string{"kids"}}, nil, nil, false},
+		{"exclusion beats inclusion", TagFilter{Include: []string{"kids"}, Exclude: []string{"nsfw"}}, []string{"kids"}, []string{"nsfw"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Normalise().Allows(tt.deckTags, tt.cardTags); got != tt.wantAllow {
+				t.Errorf("Allows(%q, %q) = %v, want %v", tt.deckTags, tt.cardTags, got, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestNormaliseTags(t *testing.T) {
+	got := NormaliseTags([]string{" NSFW", "", "nsfw", "Kids ", "  "})
+	if want := []string{"nsfw", "kids"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("normalised to %q, want %q", got, want)
+	}
+}
+
+func TestMergeDecksFiltersByTags(t *testing.T) {
+	base := &Deck{
+		ID: 1,
+		QuestionCards: []QuestionCard{
+			{Text: "Why? ____"},
+			{Text: "What ruined the party? ____", Tags: []string{"NSFW"}},
+		},
+		AnswerCards: []AnswerCard{
+			{Text: "Bees?"},
+			{Text: "Oprah.", Tags: []string{"pop"}},
+			{Text: "Something rude.", Tags: []string{"nsfw"}},
+		},
+	}
+	rude := &Deck{
+		ID:          2,
+		Tags:        []string{"nsfw"},
+		AnswerCards: []AnswerCard{{Text: "Something ruder."}},
+	}
+	kids := &Deck{
+		ID:          3,
+		Tags:        []string{"Kids"},
+		AnswerCards: []AnswerCard{{Text: "Bees?"}, {Text: "A windmill."}},
+	}
+
+	texts := func(filter TagFilter) (questions, answers []string) {
+		questionCards, answerCards := MergeDecks(filter.Normalise(), base, rude, kids)
+		for _, card := range questionCards {
+			questions = append(questions, card.Text)
+		}
+		for _, card := range answerCards {
+			answers = append(answers, card.Text)
+		}
+		return
+	}
+
+	questions, answers := texts(TagFilter{Exclude: []string{"nsfw"}})
+	if want := []string{"Why? ____"}; !reflect.DeepEqual(questions, want) {
+		t.Errorf("kept questions %q, want %q", questions, want)
+	}
+	if want := []string{"Bees?", "Oprah.", "A windmill."}; !reflect.DeepEqual(answers, want) {
+		t.Errorf("kept answers %q, want %q", answers, want)
+	}
+
+	questions, answers = texts(TagFilter{Include: []string{"kids", "pop"}})
+	if len(questions) != 0 {
+		t.Errorf("kept questions %q, want none", questions)
+	}
+	if want := []string{"Oprah.", "Bees?", "A windmill."}; !reflect.DeepEqual(answers, want) {
+		t.Errorf("kept answers %q, want %q", answers, want)
+	}
+
+	questionCards, answerCards := MergeDecks(TagFilter{}, base, kids)
+	if questionCards[0].NumAnswers != 1 || questionCards[0].DeckID != 1 {
+		t.Errorf("merged question %+v, want a pick of 1 from deck 1", questionCards[0])
+	}
+	if last := answerCards[len(answerCards)-1]; last.DeckID != 3 {
+		t.Errorf("merged answer %+v, want it from deck 3", last)
+	}
+}
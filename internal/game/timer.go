@@ -0,0 +1,159 @@
+package game
+
+import (
+	"errors"
+	"time"
+)
+
+// Clock tells the time.
+//
+// Games read the time from a clock rather than calling
+// `time.Now` directly, so that timers can be tested without
+// waiting on them.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the clock on the wall.
+type SystemClock struct{}
+
+// Now returns the current local time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SetClock changes the clock used to set and check the
+// game's deadlines.
+func (g *Game) SetClock(clock Clock) {
+	g.clock = clock
+}
+
+// now returns the time according to the game's clock.
+func (g *Game) now() time.Time {
+	if g.clock == nil {
+		return time.Now()
+	}
+	return g.clock.Now()
+}
+
+// setPhase moves the game on to a phase and starts its
+// timer, if it has one.
+func (g *Game) setPhase(phase Phase) {
+	g.Phase = phase
+
+	seconds := 0
+	switch phase {
+	case RoundInProgress:
+		seconds = g.Settings.SubmitSeconds
+	case WinnerSelection, Voting:
+		seconds = g.Settings.JudgeSeconds
+	}
+
+	if seconds > 0 {
+		g.Deadline = g.now().Add(time.Duration(seconds) * time.Second)
+	} else {
+		g.Deadline = time.Time{}
+	}
+}
+
+// Remaining returns the time left before the current
+// phase's deadline, which is never less than zero.
+//
+// Returns 0 if the phase has no deadline.
+func (g *Game) Remaining() time.Duration {
+	if g.Deadline.IsZero() {
+		return 0
+	}
+
+	remaining := g.Deadline.Sub(g.now())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Expired reports whether the current phase's deadline has
+// passed.
+func (g *Game) Expired() bool {
+	return !g.Deadline.IsZero() && !g.now().Before(g.Deadline)
+}
+
+// Expire moves the game on once the current phase's
+// deadline has passed.
+//
+// Players who have yet to submit cards have some played for
+// them from their hands at random, or sit the round out if
+// they don't hold enough. A Czar who fails to choose has a
+// winner chosen for them at random, as does a vote nobody
+// took part in. A round with nothing to judge is skipped.
+func (g *Game) Expire() (err error) {
+	if !g.Expired() {
+		return errors.New("phase has not expired")
+	}
+
+	switch g.Phase {
+	case RoundInProgress:
+		g.autoSubmit()
+		if len(g.Round.CardSubmissions) < 1 {
+			return g.startRound(g.nextCzar())
+		}
+		g.startJudging()
+	case WinnerSelection, Voting:
+		winner := g.mostVoted()
+		if winner == nil {
+			winner = g.randomSubmission()
+		}
+		if winner == nil {
+			return g.startRound(g.nextCzar())
+		}
+		g.awardRound(winner)
+	default:
+		g.Deadline = time.Time{}
+	}
+	return
+}
+
+// autoSubmit plays random cards for each player in play who
+// has yet to submit this round.
+//
+// Blank cards are never played, as there is nothing to
+// write on them. Players without enough other cards are
+// skipped.
+func (g *Game) autoSubmit() {
+	pick := g.Round.Question.Pick()
+	for _, player := range g.Players {
+		if player == g.Round.Czar || !player.active() || g.Round.Submission(player.ID) != nil {
+			continue
+		}
+
+		playable := make([]AnswerCard, 0, len(player.Hand))
+		for _, card := range player.Hand {
+			if !card.Blank {
+				playable = append(playable, *card)
+			}
+		}
+		if len(playable) < pick {
+			continue
+		}
+
+		g.random.Shuffle(len(playable), func(i, j int) {
+			playable[i], playable[j] = playable[j], playable[i]
+		})
+		played := playable[:pick]
+		for _, card := range played {
+			player.removeFromHand(card)
+		}
+		g.addSubmission(player, played)
+	}
+}
+
+// randomSubmission picks one of this round's submissions at
+// random.
+//
+// Returns nil if nothing was submitted.
+func (g *Game) randomSubmission() *CardSubmission {
+	if len(g.Round.CardSubmissions) < 1 {
+		return nil
+	}
+	return &g.Round.CardSubmissions[g.random.Intn(len(g.Round.CardSubmissions))]
+}
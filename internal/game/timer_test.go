@@ -0,0 +1,284 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock which only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+const (
+	testSubmitSeconds = 30
+	testJudgeSeconds  = 20
+)
+
+// newTimedGame starts a game with time limits on submitting
+// and judging, using a fake clock.
+func newTimedGame(t *testing.T, mode RuleMode, seed int64) (*Game, *fakeClock) {
+	t.Helper()
+
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)}
+	g := newTestGame(t, 4, seed)
+	g.SetClock(clock)
+	g.Settings.SubmitSeconds = testSubmitSeconds
+	g.Settings.JudgeSeconds = testJudgeSeconds
+	g.Settings.Mode = mode
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return g, clock
+}
+
+// blankHand replaces a player's hand with blank cards, which
+// can't be auto-played.
+func blankHand(player *Player) {
+	for i := range player.Hand {
+		player.Hand[i] = &AnswerCard{Blank: true}
+	}
+}
+
+func TestDeadlines(t *testing.T) {
+	g, clock := newTimedGame(t, Standard, 1)
+	start := clock.now
+
+	if want := start.Add(testSubmitSeconds * time.Second); !g.Deadline.Equal(want) {
+		t.Fatalf("submit deadline is %v, want %v", g.Deadline, want)
+	}
+
+	clock.advance(10 * time.Second)
+	if g.Expired() {
+		t.Error("expired before deadline")
+	}
+	if got := g.Remaining(); got != 20*time.Second {
+		t.Errorf("remaining is %v, want 20s", got)
+	}
+	if err := g.Expire(); err == nil {
+		t.Error("expired early without error")
+	}
+	if g.Phase != RoundInProgress {
+		t.Errorf("phase changed to %v before deadline", g.Phase)
+	}
+
+	submitAll(t, g)
+	if want := clock.now.Add(testJudgeSeconds * time.Second); !g.Deadline.Equal(want) {
+		t.Errorf("judge deadline is %v, want %v", g.Deadline, want)
+	}
+
+	clock.advance(time.Hour)
+	if got := g.Remaining(); got != 0 {
+		t.Errorf("remaining after deadline is %v, want 0", got)
+	}
+
+	czar := g.Round.Czar
+	if err := g.SelectWinner(czar.ID, g.Round.CardSubmissions[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if !g.Deadline.IsZero() || g.Expired() {
+		t.Errorf("end of round has deadline %v", g.Deadline)
+	}
+}
+
+func TestNoDeadlineWithoutTimeLimit(t *testing.T) {
+	g := newTestGame(t, 3, 1)
+	g.SetClock(&fakeClock{now: time.Unix(0, 0)})
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !g.Deadline.IsZero() || g.Expired() {
+		t.Errorf("untimed game has deadline %v", g.Deadline)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  RuleMode
+		setup func(t *testing.T, g *Game)
+		check func(t *testing.T, g *Game)
+	}{
+		{
+			name:  "idle players auto-play",
+			mode:  Standard,
+			setup: func(t *testing.T, g *Game) {},
+			check: func(t *testing.T, g *Game) {
+				if g.Phase != WinnerSelection {
+					t.Fatalf("phase is %v, want winner selection", g.Phase)
+				}
+				if got := len(g.Round.CardSubmissions); got != len(g.Players)-1 {
+					t.Errorf("got %d submissions, want %d", got, len(g.Players)-1)
+				}
+				for _, player := range g.Players {
+					want := g.Settings.HandSize - 1
+					if player == g.Round.Czar {
+						want = g.Settings.HandSize
+					}
+					if len(player.Hand) != want {
+						t.Errorf("player %d holds %d cards, want %d", player.ID, len(player.Hand), want)
+					}
+				}
+			},
+		},
+		{
+			name: "submissions already made are kept",
+			mode: Standard,
+			setup: func(t *testing.T, g *Game) {
+				player := g.seatAfter(g.Round.Czar)
+				if err := g.SubmitCards(player.ID, []AnswerCard{*player.Hand[0]}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			check: func(t *testing.T, g *Game) {
+				player := g.seatAfter(g.Round.Czar)
+				if len(g.Round.CardSubmissions) != len(g.Players)-1 || g.Round.CardSubmissions[0].Player != player {
+					t.Errorf("submission by player %d was not kept first", player.ID)
+				}
+			},
+		},
+		{
+			name: "players without playable cards are skipped",
+			mode: Standard,
+			setup: func(t *testing.T, g *Game) {
+				blankHand(g.seatAfter(g.Round.Czar))
+			},
+			check: func(t *testing.T, g *Game) {
+				if g.Phase != WinnerSelection {
+					t.Fatalf("phase is %v, want winner selection", g.Phase)
+				}
+				skipped := g.seatAfter(g.Round.Czar)
+				if g.Round.Submission(skipped.ID) != nil {
+					t.Error("blank hand was auto-played")
+				}
+				if got := len(g.Round.CardSubmissions); got != len(g.Players)-2 {
+					t.Errorf("got %d submissions, want %d", got, len(g.Players)-2)
+				}
+			},
+		},
+		{
+			name: "round with nothing submitted is skipped",
+			mode: Standard,
+			setup: func(t *testing.T, g *Game) {
+				for _, player := range g.Players {
+					if player != g.Round.Czar {
+						blankHand(player)
+					}
+				}
+			},
+			check: func(t *testing.T, g *Game) {
+				if g.Phase != RoundInProgress || g.Round.Number != 2 {
+					t.Errorf("in %v of round %d, want round 2 in progress", g.Phase, g.Round.Number)
+				}
+				if len(g.History) != 0 {
+					t.Errorf("skipped round has a result")
+				}
+			},
+		},
+		{
+			name: "idle czar has a random winner chosen",
+			mode: Standard,
+			setup: func(t *testing.T, g *Game) {
+				submitAll(t, g)
+			},
+			check: func(t *testing.T, g *Game) {
+				if g.Phase != EndOfRound || g.Round.Winner == nil {
+					t.Fatalf("in %v with winner %v, want end of round with a winner", g.Phase, g.Round.Winner)
+				}
+				if g.Round.Winner == g.Round.Czar || g.Round.Winner.Score != 1 {
+					t.Errorf("winner %d has score %d", g.Round.Winner.ID, g.Round.Winner.Score)
+				}
+				if len(g.History) != 1 {
+					t.Errorf("got %d results, want 1", len(g.History))
+				}
+			},
+		},
+		{
+			name: "judging with nothing to judge skips the round",
+			mode: Standard,
+			setup: func(t *testing.T, g *Game) {
+				submitAll(t, g)
+				g.Round.CardSubmissions = nil
+			},
+			check: func(t *testing.T, g *Game) {
+				if g.Phase != RoundInProgress || g.Round.Number != 2 {
+					t.Errorf("in %v of round %d, want round 2 in progress", g.Phase, g.Round.Number)
+				}
+			},
+		},
+		{
+			name: "vote nobody took part in has a random winner",
+			mode: GodIsDead,
+			setup: func(t *testing.T, g *Game) {
+				submitAll(t, g)
+			},
+			check: func(t *testing.T, g *Game) {
+				if g.Phase != EndOfRound || g.Round.Winner == nil {
+					t.Fatalf("in %v with winner %v, want end of round with a winner", g.Phase, g.Round.Winner)
+				}
+			},
+		},
+		{
+			name: "partial vote goes to the most voted",
+			mode: GodIsDead,
+			setup: func(t *testing.T, g *Game) {
+				submitAll(t, g)
+				voter := g.Players[0]
+				target := g.Round.Submission(g.Players[2].ID)
+				if err := g.CastVote(voter.ID, target.ID); err != nil {
+					t.Fatal(err)
+				}
+			},
+			check: func(t *testing.T, g *Game) {
+				if g.Round.Winner != g.Players[2] {
+					t.Errorf("winner is %v, want player %d", g.Round.Winner, g.Players[2].ID)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, clock := newTimedGame(t, tt.mode, 1)
+			tt.setup(t, g)
+
+			clock.advance(time.Hour)
+			if !g.Expired() {
+				t.Fatal("not expired after deadline")
+			}
+			if err := g.Expire(); err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, g)
+		})
+	}
+}
+
+func TestExpireIsDeterministic(t *testing.T) {
+	winners := map[int]bool{}
+	for i := 0; i < 2; i++ {
+		g, clock := newTimedGame(t, Standard, 99)
+		clock.advance(time.Hour)
+		if err := g.Expire(); err != nil {
+			t.Fatal(err)
+		}
+		clock.advance(time.Hour)
+		if err := g.Expire(); err != nil {
+			t.Fatal(err)
+		}
+		winners[g.Round.Winner.ID] = true
+	}
+
+	if len(winners) != 1 {
+		t.Errorf("same seed chose different winners: %v", winners)
+	}
+}
@@ -0,0 +1,185 @@
+package game
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxCardTextLength is the longest text, in characters, that
+// a card may hold.
+const MaxCardTextLength int = 300
+
+// MinAnswerRatio is the smallest ratio of answer cards to
+// question cards a deck should have to play well.
+const MinAnswerRatio float64 = 3
+
+var blankPattern = regexp.MustCompile(`_+`)
+
+// CountBlanks counts the blanks in question text, where each
+// run of underscores is one blank.
+func CountBlanks(text string) int {
+	return len(blankPattern.FindAllStringIndex(text, -1))
+}
+
+// InferNumAnswers sets the number of answers for each question
+// card in the deck which lacks one, from the number of blanks
+// in its text.
+//
+// Questions without blanks ask for a single answer.
+func InferNumAnswers(d *Deck) {
+	for i := range d.QuestionCards {
+		card := &d.QuestionCards[i]
+		if card.NumAnswers != 0 {
+			continue
+		}
+
+		card.NumAnswers = CountBlanks(card.Text)
+		if card.NumAnswers < 1 {
+			card.NumAnswers = 1
+		}
+	}
+}
+
+// Severity indicates how serious a problem with a deck is.
+type Severity int
+
+const (
+	// Warning is a problem which doesn't stop the deck being
+	// played.
+	Warning Severity = iota
+
+	// Error is a problem which must be fixed before the deck
+	// is used.
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Issue is a single problem found in a deck.
+type Issue struct {
+	Severity Severity
+
+	// Kind is "question" or "answer" for problems with a card,
+	// or "deck" for problems with the deck as a whole.
+	Kind string
+
+	// Index is the position of the card in its list.
+	Index int
+
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Kind == "deck" {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s %d: %s", i.Severity, i.Kind, i.Index, i.Message)
+}
+
+// Report is the result of validating a deck.
+type Report struct {
+	Issues       []Issue
+	NumQuestions int
+	NumAnswers   int
+
+	// AnswerRatio is the number of answer cards for each
+	// question card.
+	AnswerRatio float64
+}
+
+// HasErrors reports whether any issue is an `Error`.
+func (r *Report) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Report) add(severity Severity, kind string, index int, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{
+		Severity: severity,
+		Kind:     kind,
+		Index:    index,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// ValidateDeck checks a deck for problems before it is used.
+//
+// The number of answers is inferred for question cards which
+// lack one. Cards which are empty, overlong, draw too many
+// or whose number of answers doesn't match their blanks are
+// errors. Repeated cards and a low ratio of answers to
+// questions are warnings.
+func ValidateDeck(d *Deck) (report Report) {
+	InferNumAnswers(d)
+
+	report.NumQuestions = len(d.QuestionCards)
+	report.NumAnswers = len(d.AnswerCards)
+
+	seen := map[string]int{}
+	for i, card := range d.QuestionCards {
+		report.checkText("question", i, card.Text, seen)
+
+		if card.NumAnswers < 1 || card.NumAnswers > MaxNumAnswers {
+			report.add(Error, "question", i, "pick must be between 1 and %d, got %d", MaxNumAnswers, card.NumAnswers)
+		} else if blanks := CountBlanks(card.Text); blanks > 0 && blanks != card.NumAnswers {
+			report.add(Error, "question", i, "has %d blanks but picks %d", blanks, card.NumAnswers)
+		}
+
+		if card.Draw < 0 || card.Draw > MaxDraw {
+			report.add(Error, "question", i, "draw must be between 0 and %d, got %d", MaxDraw, card.Draw)
+		}
+	}
+
+	seen = map[string]int{}
+	for i, card := range d.AnswerCards {
+		report.checkText("answer", i, card.Text, seen)
+	}
+
+	if report.NumQuestions < 1 {
+		report.add(Error, "deck", 0, "deck has no question cards")
+	}
+	if report.NumAnswers < 1 {
+		report.add(Error, "deck", 0, "deck has no answer cards")
+	}
+
+	if report.NumQuestions > 0 {
+		report.AnswerRatio = float64(report.NumAnswers) / float64(report.NumQuestions)
+		if report.AnswerRatio < MinAnswerRatio {
+			report.add(Warning, "deck", 0, "only %.1f answers per question, at least %.0f recommended", report.AnswerRatio, MinAnswerRatio)
+		}
+	}
+	return
+}
+
+// checkText reports empty, overlong and repeated card text.
+//
+// `seen` maps normalised text to the index of the first card
+// of the same kind to use it.
+func (r *Report) checkText(kind string, index int, text string, seen map[string]int) {
+	normalised := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	if normalised == "" {
+		r.add(Error, kind, index, "text is empty")
+		return
+	}
+
+	if length := utf8.RuneCountInString(text); length > MaxCardTextLength {
+		r.add(Error, kind, index, "text is %d characters, at most %d allowed", length, MaxCardTextLength)
+	}
+
+	if first, ok := seen[normalised]; ok {
+		r.add(Warning, kind, index, "repeats %s %d", kind, first)
+		return
+	}
+	seen[normalised] = index
+}
@@ -0,0 +1,176 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// validDeck returns a deck without problems, with three
+// answers for each question.
+func validDeck() Deck {
+	return Deck{
+		QuestionCards: []QuestionCard{
+			{Text: "Why can't I sleep at night?"},
+			{Text: "____ + ____ = ____.", NumAnswers: 3, Draw: 2},
+		},
+		AnswerCards: []AnswerCard{
+			{Text: "Bees?"},
+			{Text: "Oprah."},
+			{Text: "Vigorous jazz hands."},
+			{Text: "A windmill."},
+			{Text: "The Pope."},
+			{Text: "Her Majesty the Queen."},
+		},
+	}
+}
+
+func TestValidateDeck(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(d *Deck)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			change: func(d *Deck) {},
+		},
+		{
+			name:   "empty question",
+			change: func(d *Deck) { d.QuestionCards[0].Text = " \t" },
+			want:   []string{"error: question 0: text is empty"},
+		},
+		{
+			name:   "empty answer",
+			change: func(d *Deck) { d.AnswerCards[2].Text = "" },
+			want:   []string{"error: answer 2: text is empty"},
+		},
+		{
+			name:   "overlong answer",
+			change: func(d *Deck) { d.AnswerCards[1].Text = strings.Repeat("é", MaxCardTextLength+1) },
+			want:   []string{"error: answer 1: text is 301 characters, at most 300 allowed"},
+		},
+		{
+			name:   "longest answer",
+			change: func(d *Deck) { d.AnswerCards[1].Text = strings.Repeat("é", MaxCardTextLength) },
+		},
+		{
+			name:   "pick too large",
+			change: func(d *Deck) { d.QuestionCards[0].NumAnswers = MaxNumAnswers + 1 },
+			want:   []string{"error: question 0: pick must be between 1 and 3, got 4"},
+		},
+		{
+			name:   "negative pick",
+			change: func(d *Deck) { d.QuestionCards[0].NumAnswers = -1 },
+			want:   []string{"error: question 0: pick must be between 1 and 3, got -1"},
+		},
+		{
+			name:   "pick not matching blanks",
+			change: func(d *Deck) { d.QuestionCards[1].NumAnswers = 2 },
+			want:   []string{"error: question 1: has 3 blanks but picks 2"},
+		},
+		{
+			name:   "pick without blanks",
+			change: func(d *Deck) { d.QuestionCards[0].NumAnswers = 2 },
+		},
+		{
+			name:   "draw too large",
+			change: func(d *Deck) { d.QuestionCards[1].Draw = MaxDraw + 1 },
+			want:   []string{"error: question 1: draw must be between 0 and 3, got 4"},
+		},
+		{
+			name:   "negative draw",
+			change: func(d *Deck) { d.QuestionCards[0].Draw = -1 },
+			want:   []string{"error: question 0: draw must be between 0 and 3, got -1"},
+		},
+		{
+			name:   "repeated question",
+			change: func(d *Deck) { d.QuestionCards[1] = QuestionCard{Text: "why can't  I sleep at NIGHT?"} },
+			want:   []string{"warning: question 1: repeats question 0"},
+		},
+		{
+			name:   "repeated answer",
+			change: func(d *Deck) { d.AnswerCards[4].Text = "bees?" },
+			want:   []string{"warning: answer 4: repeats answer 0"},
+		},
+		{
+			name: "question text used as answer",
+			change: func(d *Deck) {
+				d.AnswerCards[0].Text = d.QuestionCards[0].Text
+			},
+		},
+		{
+			name:   "no questions",
+			change: func(d *Deck) { d.QuestionCards = nil },
+			want:   []string{"error: deck has no question cards"},
+		},
+		{
+			name:   "no answers",
+			change: func(d *Deck) { d.AnswerCards = nil },
+			want: []string{
+				"error: deck has no answer cards",
+				"warning: only 0.0 answers per question, at least 3 recommended",
+			},
+		},
+		{
+			name:   "few answers",
+			change: func(d *Deck) { d.AnswerCards = d.AnswerCards[:5] },
+			want:   []string{"warning: only 2.5 answers per question, at least 3 recommended"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deck := validDeck()
+			tt.change(&deck)
+
+			report := ValidateDeck(&deck)
+			got := []string{}
+			wantErrors := false
+			for _, issue := range report.Issues {
+				got = append(got, issue.String())
+			}
+			for _, issue := range tt.want {
+				wantErrors = wantErrors || strings.HasPrefix(issue, "error")
+			}
+			if tt.want == nil {
+				tt.want = []string{}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got issues %q, want %q", got, tt.want)
+			}
+			if report.HasErrors() != wantErrors {
+				t.Errorf("HasErrors() = %v, want %v", report.HasErrors(), wantErrors)
+			}
+		})
+	}
+}
+
+func TestValidateDeckCounts(t *testing.T) {
+	deck := validDeck()
+	report := ValidateDeck(&deck)
+
+	if report.NumQuestions != 2 || report.NumAnswers != 6 || report.AnswerRatio != 3 {
+		t.Errorf("got %d questions, %d answers and ratio %v, want 2, 6 and 3",
+			report.NumQuestions, report.NumAnswers, report.AnswerRatio)
+	}
+}
+
+func TestInferNumAnswers(t *testing.T) {
+	deck := Deck{QuestionCards: []QuestionCard{
+		{Text: "Why can't I sleep at night?"},
+		{Text: "____ + ____ = ____."},
+		{Text: "Step 1: ______. Step 2: __."},
+		{Text: "____ is ____.", NumAnswers: 1},
+	}}
+
+	InferNumAnswers(&deck)
+	got := []int{}
+	for _, card := range deck.QuestionCards {
+		got = append(got, card.NumAnswers)
+	}
+	if want := []int{1, 3, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("inferred picks %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,184 @@
+package game
+
+import (
+	"sort"
+	"time"
+)
+
+// View is a single player's view of a game.
+//
+// Other players' hands are redacted, as are the cards
+// submitted by other players before the Czar's turn. Who
+// made each submission is hidden until a winner is chosen.
+type View struct {
+	ID       int           `json:"id"`
+	Name     string        `json:"name"`
+	Phase    Phase         `json:"phase"`
+	Owner    int           `json:"owner"`
+	Players  []PlayerView  `json:"players"`
+	Hand     []AnswerCard  `json:"hand"`
+	Round    *RoundView    `json:"round,omitempty"`
+	Settings GameSettings  `json:"settings"`
+	Pool     *PoolView     `json:"pool,omitempty"`
+	History  []RoundResult `json:"history"`
+
+	// Deadline is when the current phase times out, if it
+	// has a time limit.
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
+
+// PlayerView is the public portion of a player's state.
+type PlayerView struct {
+	ID         int    `json:"id"`
+	Username   string `json:"username"`
+	Score      int    `json:"score"`
+	HandSize   int    `json:"handSize"`
+	Submitted  bool   `json:"submitted"`
+	Bot        bool   `json:"bot,omitempty"`
+	Mulliganed bool   `json:"mulliganed"`
+	Voted      bool   `json:"voted"`
+	Eliminated bool   `json:"eliminated"`
+}
+
+// PoolView counts the cards which will be put into play
+// once the tag filter has been applied, including blanks.
+type PoolView struct {
+	NumQuestions int `json:"numQuestions"`
+	NumAnswers   int `json:"numAnswers"`
+}
+
+// RoundView is the visible portion of the current round.
+type RoundView struct {
+	Number      int              `json:"number"`
+	Czar        int              `json:"czar"`
+	Question    *QuestionCard    `json:"question"`
+	Submissions []SubmissionView `json:"submissions"`
+	Winner      int              `json:"winner,omitempty"`
+
+	// Vote is the ID of the submission the viewer voted for,
+	// if any.
+	Vote string `json:"vote,omitempty"`
+}
+
+// SubmissionView is a visible card submission.
+//
+// The player who made it and the votes it received are only
+// shown once the round has been won.
+type SubmissionView struct {
+	ID       string       `json:"id"`
+	PlayerID int          `json:"playerId,omitempty"`
+	Own      bool         `json:"own,omitempty"`
+	Cards    []AnswerCard `json:"cards"`
+	Votes    int          `json:"votes,omitempty"`
+}
+
+// ViewFor builds the view of the game seen by the given
+// player.
+func (g *Game) ViewFor(playerID int) (view View) {
+	view = View{
+		ID:       g.ID,
+		Name:     g.Name,
+		Phase:    g.Phase,
+		Players:  make([]PlayerView, 0, len(g.Players)),
+		Hand:     []AnswerCard{},
+		Settings: g.Settings,
+		History:  g.History,
+	}
+
+	if !g.Deadline.IsZero() {
+		deadline := g.Deadline
+		view.Deadline = &deadline
+	}
+
+	if view.History == nil {
+		view.History = []RoundResult{}
+	}
+
+	if g.Owner != nil {
+		view.Owner = g.Owner.ID
+	}
+
+	for _, player := range g.Players {
+		pv := PlayerView{
+			ID:         player.ID,
+			Username:   player.Username,
+			Score:      player.Score,
+			HandSize:   len(player.Hand),
+			Bot:        player.Bot,
+			Mulliganed: player.Mulliganed,
+			Eliminated: player.Eliminated,
+		}
+		if g.Round != nil {
+			pv.Submitted = g.Round.Submission(player.ID) != nil
+			pv.Voted = g.Round.Vote(player.ID) != nil
+		}
+		view.Players = append(view.Players, pv)
+
+		if player.ID == playerID {
+			for _, card := range player.Hand {
+				view.Hand = append(view.Hand, *card)
+			}
+		}
+	}
+
+	if g.Phase == Lobby {
+		questions, answers := MergeDecks(g.Settings.TagFilter, g.Decks...)
+		view.Pool = &PoolView{
+			NumQuestions: len(questions),
+			NumAnswers:   len(answers) + g.Settings.NumBlanks,
+		}
+	}
+
+	if g.Round != nil && g.Phase != Lobby {
+		view.Round = g.Round.viewFor(playerID, g.Phase)
+	}
+	return
+}
+
+// viewFor builds the view of the round seen by the given
+// player during the given phase.
+func (r *Round) viewFor(playerID int, phase Phase) *RoundView {
+	view := &RoundView{
+		Number:      r.Number,
+		Question:    r.Question,
+		Submissions: []SubmissionView{},
+	}
+
+	if r.Czar != nil {
+		view.Czar = r.Czar.ID
+	}
+
+	if r.Winner != nil {
+		view.Winner = r.Winner.ID
+	}
+
+	if vote := r.Vote(playerID); vote != nil {
+		if submission := r.Submission(vote.Player.ID); submission != nil {
+			view.Vote = submission.ID
+		}
+	}
+
+	for _, submission := range r.CardSubmissions {
+		own := submission.Player.ID == playerID
+		if phase == RoundInProgress && !own {
+			continue
+		}
+		sv := SubmissionView{
+			ID:    submission.ID,
+			Own:   own,
+			Cards: submission.Cards,
+		}
+		if r.Winner != nil {
+			sv.PlayerID = submission.Player.ID
+			sv.Votes = r.NumVotes(submission.Player.ID)
+		}
+		view.Submissions = append(view.Submissions, sv)
+	}
+
+	// Random IDs give an order unrelated to who submitted
+	// first.
+	sort.Slice(view.Submissions, func(i, j int) bool {
+		return view.Submissions[i].ID < view.Submissions[j].ID
+	})
+	return view
+}
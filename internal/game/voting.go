@@ -0,0 +1,169 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// RuleMode decides how the winner of each round is chosen.
+type RuleMode int
+
+const (
+	// Standard play has a Czar choose the winner.
+	Standard RuleMode = iota
+
+	// GodIsDead has no Czar. Every player votes for their
+	// favourite submission instead.
+	GodIsDead
+)
+
+var ruleModeNames = [...]string{"standard", "godIsDead"}
+
+// String returns the name of the rule mode.
+func (m RuleMode) String() string {
+	if int(m) < 0 || int(m) >= len(ruleModeNames) {
+		return "unknown"
+	}
+	return ruleModeNames[m]
+}
+
+// MarshalJSON attempts to serialise the rule mode as a JSON string.
+func (m RuleMode) MarshalJSON() (result []byte, err error) {
+	if int(m) < 0 || int(m) >= len(ruleModeNames) {
+		return nil, errors.New("invalid rule mode")
+	}
+	return json.Marshal(ruleModeNames[m])
+}
+
+// UnmarshalJSON attempts to deserialise rule mode from a JSON string.
+func (m *RuleMode) UnmarshalJSON(input []byte) (err error) {
+	var name string
+	err = json.Unmarshal(input, &name)
+	if err != nil {
+		return err
+	}
+
+	for i, option := range ruleModeNames {
+		if option == name {
+			*m = RuleMode(i)
+			return nil
+		}
+	}
+	return errors.New("invalid RuleMode value")
+}
+
+// Vote is a player's vote for another player's submission.
+type Vote struct {
+	Voter  *Player
+	Player *Player
+}
+
+// CastVote records a player's vote for the submission with
+// the given ID.
+//
+// Players may vote once per round, and not for their own
+// submission. Once every human player in play has voted, the round
+// goes to the submission with the most votes. Ties go to
+// whichever of the tied submissions was made first.
+func (g *Game) CastVote(voterID int, submissionID string) (err error) {
+	if g.Phase != Voting {
+		return errors.New("can't vote outside voting phase")
+	}
+
+	voter := g.Player(voterID)
+	if voter == nil || voter.Bot {
+		return errors.New("player not in game")
+	}
+
+	if !voter.active() {
+		return errors.New("eliminated players can't vote")
+	}
+
+	if g.Round.Vote(voterID) != nil {
+		return errors.New("player has already voted")
+	}
+
+	submission := g.Round.SubmissionByID(submissionID)
+	if submission == nil {
+		return errors.New("no such submission")
+	}
+
+	if submission.Player == voter {
+		return errors.New("can't vote for own submission")
+	}
+
+	g.Round.Votes = append(g.Round.Votes, Vote{
+		Voter:  voter,
+		Player: submission.Player,
+	})
+
+	g.checkVotes()
+	return
+}
+
+// Vote retrieves the vote cast by a player this round.
+//
+// Returns nil if the player has yet to vote.
+func (r *Round) Vote(voterID int) *Vote {
+	for i := range r.Votes {
+		if r.Votes[i].Voter.ID == voterID {
+			return &r.Votes[i]
+		}
+	}
+	return nil
+}
+
+// NumVotes counts the votes cast for a player's submission.
+func (r *Round) NumVotes(playerID int) (n int) {
+	for _, vote := range r.Votes {
+		if vote.Player.ID == playerID {
+			n++
+		}
+	}
+	return
+}
+
+// removeVotes drops the votes cast by or for a player.
+func (r *Round) removeVotes(player *Player) {
+	votes := r.Votes[:0]
+	for _, vote := range r.Votes {
+		if vote.Voter != player && vote.Player != player {
+			votes = append(votes, vote)
+		}
+	}
+	r.Votes = votes
+}
+
+// checkVotes awards the round once every human player still
+// in play has voted.
+func (g *Game) checkVotes() {
+	for _, player := range g.activeHumans() {
+		if g.Round.Vote(player.ID) == nil {
+			return
+		}
+	}
+
+	winner := g.mostVoted()
+	if winner == nil && len(g.Round.CardSubmissions) > 0 {
+		winner = &g.Round.CardSubmissions[0]
+	}
+
+	if winner != nil {
+		g.awardRound(winner)
+	}
+}
+
+// mostVoted finds the submission with the most votes, with
+// ties going to whichever was made first.
+//
+// Returns nil if no votes have been cast.
+func (g *Game) mostVoted() (winner *CardSubmission) {
+	most := 0
+	for i := range g.Round.CardSubmissions {
+		submission := &g.Round.CardSubmissions[i]
+		if votes := g.Round.NumVotes(submission.Player.ID); votes > most {
+			winner, most = submission, votes
+		}
+	}
+	return
+}
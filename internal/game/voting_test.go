@@ -0,0 +1,102 @@
+package game
+
+import "testing"
+
+// newVotingGame starts a game without a Czar, in which all
+// four players have submitted, earliest first.
+func newVotingGame(t *testing.T) (*Game, []*Player) {
+	t.Helper()
+
+	g := newTestGame(t, 4, 1)
+	g.Settings.Mode = GodIsDead
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+	submitAll(t, g)
+	if g.Phase != Voting {
+		t.Fatalf("in %v, want voting", g.Phase)
+	}
+
+	submitters := []*Player{}
+	for _, submission := range g.Round.CardSubmissions {
+		submitters = append(submitters, submission.Player)
+	}
+	return g, submitters
+}
+
+// vote has a player vote for another player's submission.
+func vote(t *testing.T, g *Game, voter, target *Player) {
+	t.Helper()
+
+	if err := g.CastVote(voter.ID, g.Round.Submission(target.ID).ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMostVotesWins(t *testing.T) {
+	g, p := newVotingGame(t)
+
+	vote(t, g, p[0], p[3])
+	vote(t, g, p[1], p[3])
+	vote(t, g, p[2], p[3])
+	if g.Phase != Voting {
+		t.Fatalf("round ended in %v before everyone voted", g.Phase)
+	}
+	vote(t, g, p[3], p[0])
+
+	if g.Round.Winner != p[3] {
+		t.Errorf("round won by %v, want player %d", g.Round.Winner, p[3].ID)
+	}
+}
+
+func TestVoteTieGoesToEarliestSubmission(t *testing.T) {
+	g, p := newVotingGame(t)
+
+	// The second and third submissions tie on two votes each.
+	vote(t, g, p[0], p[2])
+	vote(t, g, p[1], p[2])
+	vote(t, g, p[2], p[1])
+	vote(t, g, p[3], p[1])
+
+	if g.Round.Winner != p[1] {
+		t.Errorf("round won by %v, want player %d", g.Round.Winner, p[1].ID)
+	}
+}
+
+func TestVoteRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		vote func(g *Game, p []*Player) error
+	}{
+		{"own submission", func(g *Game, p []*Player) error {
+			return g.CastVote(p[1].ID, g.Round.Submission(p[1].ID).ID)
+		}},
+		{"second vote", func(g *Game, p []*Player) error {
+			return g.CastVote(p[0].ID, g.Round.Submission(p[2].ID).ID)
+		}},
+		{"no such submission", func(g *Game, p []*Player) error {
+			return g.CastVote(p[1].ID, "missing")
+		}},
+		{"not in game", func(g *Game, p []*Player) error {
+			return g.CastVote(99, g.Round.Submission(p[1].ID).ID)
+		}},
+		{"outside voting", func(g *Game, p []*Player) error {
+			g.Phase = WinnerSelection
+			return g.CastVote(p[1].ID, g.Round.Submission(p[2].ID).ID)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, p := newVotingGame(t)
+			vote(t, g, p[0], p[1])
+
+			if err := tt.vote(g, p); err == nil {
+				t.Fatal("vote was accepted")
+			}
+			if len(g.Round.Votes) != 1 || g.Round.Votes[0].Voter != p[0] || g.Round.Votes[0].Player != p[1] {
+				t.Errorf("votes changed to %+v", g.Round.Votes)
+			}
+		})
+	}
+}
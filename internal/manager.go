@@ -1,34 +1,126 @@
 package internal
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"time"
 
-	"github.com/gorilla/websocket"
-
+	"github.com/rjacobs31/trees-against-humanity-server/internal/api"
 	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/messages"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/storage"
 )
 
 // Hub controls all of the active games and users
 // connected to them.
 type Hub struct {
 	Users       map[int]User
-	clients     map[*Client]User
-	Games       map[int]game.Game
+	clients     map[*Client]int
+	Games       map[int]*game.Game
+	subscribers map[int]map[*Client]bool
 	userCounter int
 	gameCounter int
 
+	// Persists games between restarts. May be nil.
+	repo *storage.GameRepository
+
+	// Library of decks which games may be played with.
+	decks *storage.DeckRepository
+
 	// Registers clients.
 	register chan *Client
 
 	// Unregisters cliens.
 	unregister chan *Client
+
+	// Carries messages read from clients.
+	incoming chan clientMessage
+
+	// Carries functions to be run on the Hub's goroutine.
+	actions chan func()
+
+	// Tells the time for games' deadlines.
+	clock game.Clock
+
+	// Fires whenever deadlines should be checked.
+	ticks <-chan time.Time
+}
+
+// NewHub creates a Hub which saves its games to the given
+// repository and plays with decks from the given library.
+//
+// Games saved before the last shutdown are restored, along
+// with entries for their players so that they may reconnect.
+// Finished games are discarded.
+func NewHub(repo *storage.GameRepository, decks *storage.DeckRepository) (h *Hub, err error) {
+	h = &Hub{
+		Users:       make(map[int]User),
+		clients:     make(map[*Client]int),
+		Games:       make(map[int]*game.Game),
+		subscribers: make(map[int]map[*Client]bool),
+		repo:        repo,
+		decks:       decks,
+		register:    make(chan *Client),
+		unregister:  make(chan *Client),
+		incoming:    make(chan clientMessage),
+		actions:     make(chan func()),
+		clock:       game.SystemClock{},
+	}
+
+	if repo == nil {
+		return h, nil
+	}
+
+	games, err := repo.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, g := range games {
+		if g.Phase == game.EndOfGame || g.NumHumans() < 1 {
+			if err := repo.Delete(g.ID); err != nil {
+				log.Println(err)
+			}
+			continue
+		}
+
+		g.SetClock(h.clock)
+		h.Games[g.ID] = g
+		if g.ID > h.gameCounter {
+			h.gameCounter = g.ID
+		}
+
+		for _, player := range g.Players {
+			if player.Bot {
+				continue
+			}
+			h.Users[player.ID] = User{Username: player.Username}
+			if player.ID > h.userCounter {
+				h.userCounter = player.ID
+			}
+		}
+	}
+
+	log.Printf("Restored %d games", len(h.Games))
+	return h, nil
 }
 
 // Run starts up the Hub instance and listens for
 // client requests.
 func (h *Hub) Run() {
+	if h.Users == nil {
+		h.Users = make(map[int]User)
+	}
 	if h.clients == nil {
-		h.clients = make(map[*Client]User)
+		h.clients = make(map[*Client]int)
+	}
+	if h.Games == nil {
+		h.Games = make(map[int]*game.Game)
+	}
+	if h.subscribers == nil {
+		h.subscribers = make(map[int]map[*Client]bool)
 	}
 	if h.register == nil {
 		h.register = make(chan *Client)
@@ -36,43 +128,159 @@ func (h *Hub) Run() {
 	if h.unregister == nil {
 		h.unregister = make(chan *Client)
 	}
+	if h.incoming == nil {
+		h.incoming = make(chan clientMessage)
+	}
+	if h.actions == nil {
+		h.actions = make(chan func())
+	}
+	if h.clock == nil {
+		h.clock = game.SystemClock{}
+	}
+	if h.ticks == nil {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		h.ticks = ticker.C
+	}
 
 	for {
 		select {
 		case client := <-h.register:
-			h.clients[client] = User{Client: client}
+			h.clients[client] = 0
+			h.handleConnect(client)
 		case client := <-h.unregister:
+			h.detach(client)
+			for _, group := range h.subscribers {
+				delete(group, client)
+			}
 			delete(h.clients, client)
 			close(client.send)
+		case message := <-h.incoming:
+			h.dispatch(message.client, message.data)
+		case action := <-h.actions:
+			action()
+		case <-h.ticks:
+			h.tick()
+		}
+	}
+}
+
+// tick moves on any games whose deadlines have passed, and
+// counts down the time left in the rest.
+func (h *Hub) tick() {
+	for gameID, g := range h.Games {
+		if g.Deadline.IsZero() {
+			continue
+		}
+
+		if !g.Expired() {
+			h.broadcast(gameID, messages.OutgoingMessage{
+				Type: messages.Countdown,
+				Data: messages.CountdownData{
+					GameID:    gameID,
+					Phase:     g.Phase,
+					Deadline:  g.Deadline,
+					Remaining: int(g.Remaining().Round(time.Second) / time.Second),
+				},
+			})
+			continue
+		}
+
+		round := g.Round.Number
+		if err := g.Expire(); err != nil {
+			log.Printf("Could not expire game %d: %v", gameID, err)
+			continue
+		}
+
+		h.updateGame(gameID)
+		if g.Round.Number == round && g.Round.Winner != nil {
+			h.broadcastResult(gameID)
 		}
 	}
 }
 
+// do runs a function on the Hub's goroutine and waits for it
+// to finish, so that it may safely access the Hub's state.
+func (h *Hub) do(f func()) {
+	done := make(chan struct{})
+	h.actions <- func() {
+		f()
+		close(done)
+	}
+	<-done
+}
+
 // AddUser attempts to insert a user into the map of active
 // users for the `Manager`.
 //
-// The username must not already be in use.
-func (h *Hub) AddUser(username string, conn *websocket.Conn) (err error) {
-	if conn == nil {
-		return errors.New("user must have a websocket connection")
-	}
-
-	if len(username) < 4 {
-		return errors.New("username must be at least 4 characters")
+// The username must not already be in use. The client may
+// be nil for users who have yet to open a websocket.
+func (h *Hub) AddUser(username string, client *Client) (id int, err error) {
+	if err = api.ValidateUsername(username); err != nil {
+		return 0, err
 	}
 
 	for _, u := range h.Users {
 		if u.Username == username {
-			return errors.New("username already taken")
+			return 0, errors.New("username already taken")
 		}
 	}
 
 	h.userCounter++
 	h.Users[h.userCounter] = User{
+		Client:   client,
 		Username: username,
 	}
+	if client != nil {
+		h.clients[client] = h.userCounter
+	}
 
-	return nil
+	return h.userCounter, nil
+}
+
+// ConnectUser ties a client to the user entry for its
+// logged in username, creating the entry if necessary.
+//
+// A client previously tied to the same user is closed. The
+// new client is subscribed to all of the user's games.
+func (h *Hub) ConnectUser(client *Client) (id int, err error) {
+	id = h.userID(client.username)
+	if id == 0 {
+		return h.AddUser(client.username, client)
+	}
+
+	user := h.Users[id]
+	if user.Client != nil && user.Client != client {
+		h.clients[user.Client] = 0
+		for _, group := range h.subscribers {
+			delete(group, user.Client)
+		}
+		user.Client.connection.Close()
+	}
+
+	user.Client = client
+	h.Users[id] = user
+	h.clients[client] = id
+
+	for gameID, g := range h.Games {
+		if g.Player(id) != nil {
+			h.subscribe(gameID, client)
+			h.broadcastGame(gameID)
+		}
+	}
+	return id, nil
+}
+
+// userID finds the ID of the user with the given username.
+//
+// Returns 0 if there is no such user.
+func (h *Hub) userID(username string) int {
+	for id, user := range h.Users {
+		if user.Username == username {
+			return id
+		}
+	}
+	return 0
 }
 
 // RemoveUser attempts to remove a user from the
@@ -82,12 +290,15 @@ func (h *Hub) RemoveUser(id int) (err error) {
 		return errors.New("must specify an ID above 0")
 	}
 
-	_, ok := h.Users[id]
+	user, ok := h.Users[id]
 
 	if !ok {
 		return errors.New("user to remove does not exist")
 	}
 
+	if user.Client != nil {
+		h.clients[user.Client] = 0
+	}
 	delete(h.Users, id)
 
 	return nil
@@ -96,21 +307,58 @@ func (h *Hub) RemoveUser(id int) (err error) {
 // AddGame attempts to insert a game into the map of active
 // games for the `Manager`.
 //
-// The game name must not already be in use.
-func (h *Hub) AddGame(userID int, name, password string) (err error) {
-	_, ok := h.Users[userID]
+// The user creating the game becomes its owner. The game
+// is played with the library decks with the given IDs.
+func (h *Hub) AddGame(userID int, name, password string, deckIDs []int) (id int, err error) {
+	user, ok := h.Users[userID]
 	if !ok {
-		return errors.New("invalid owner ID")
+		return 0, errors.New("invalid owner ID")
+	}
+
+	for _, g := range h.Games {
+		if g.Name == name {
+			return 0, errors.New("game name already taken")
+		}
+	}
+
+	owner := &game.Player{
+		ID:       userID,
+		Username: user.Username,
+	}
+	g, err := game.Create(h.gameCounter+1, name, password, owner)
+	if err != nil {
+		return 0, err
+	}
+
+	g.Decks, err = h.loadDecks(deckIDs)
+	if err != nil {
+		return 0, err
 	}
+	g.Settings.DeckIDs = append([]int{}, deckIDs...)
+	g.SetClock(h.clock)
 
 	h.gameCounter++
-	h.Games[h.gameCounter] = game.Game{
-		ID:       h.gameCounter,
-		Name:     name,
-		Password: password,
+	h.Games[h.gameCounter] = g
+	h.subscribe(h.gameCounter, user.Client)
+	h.saveGame(g)
+
+	return h.gameCounter, nil
+}
+
+// loadDecks retrieves decks from the library by ID.
+func (h *Hub) loadDecks(ids []int) (decks []*game.Deck, err error) {
+	if len(ids) > 0 && h.decks == nil {
+		return nil, errors.New("no deck library available")
 	}
 
-	return nil
+	for _, id := range ids {
+		deck, err := h.decks.Get(id)
+		if err != nil {
+			return nil, fmt.Errorf("deck %d: %v", id, err)
+		}
+		decks = append(decks, deck)
+	}
+	return decks, nil
 }
 
 // RemoveGame attempts to remove a game from the
@@ -122,6 +370,210 @@ func (h *Hub) RemoveGame(id int) (err error) {
 	}
 
 	delete(h.Games, id)
+	delete(h.subscribers, id)
+
+	if h.repo != nil {
+		if err := h.repo.Delete(id); err != nil {
+			log.Println(err)
+		}
+	}
 
 	return nil
 }
+
+// JoinGame attempts to add a user to an existing game.
+func (h *Hub) JoinGame(userID, gameID int, password string) (err error) {
+	user, ok := h.Users[userID]
+	if !ok {
+		return errors.New("invalid user ID")
+	}
+
+	g, ok := h.Games[gameID]
+	if !ok {
+		return errors.New("invalid game ID")
+	}
+
+	err = g.Join(&game.Player{ID: userID, Username: user.Username}, password)
+	if err != nil {
+		return err
+	}
+
+	h.subscribe(gameID, user.Client)
+	h.updateGame(gameID)
+	return nil
+}
+
+// LeaveGame attempts to remove a user from a joined game.
+//
+// The game is removed once its last human player has left.
+// If the player leaving settles the round, such as by being
+// the last to vote, the result is announced.
+func (h *Hub) LeaveGame(userID, gameID int) (err error) {
+	g, ok := h.Games[gameID]
+	if !ok {
+		return errors.New("invalid game ID")
+	}
+
+	rounds := len(g.History)
+	err = g.Leave(userID)
+	if err != nil {
+		return err
+	}
+
+	if user, ok := h.Users[userID]; ok {
+		h.unsubscribe(gameID, user.Client)
+	}
+
+	if g.NumHumans() < 1 {
+		return h.RemoveGame(gameID)
+	}
+
+	h.updateGame(gameID)
+	if len(g.History) > rounds {
+		h.broadcastResult(gameID)
+	}
+	return nil
+}
+
+// UpdateSettings applies a JSON patch to the settings of a
+// game on behalf of its owner.
+//
+// Fields missing from the patch are left as they are. Decks
+// are reloaded from the library if the selection changes.
+func (h *Hub) UpdateSettings(userID, gameID int, patch json.RawMessage) (settings *game.GameSettings, err error) {
+	g, ok := h.Games[gameID]
+	if !ok {
+		return nil, api.ErrGameNotFound
+	}
+
+	if g.Owner == nil || g.Owner.ID != userID {
+		return nil, api.ErrNotGameOwner
+	}
+
+	// The patch is decoded into a deep copy, as decoding reuses
+	// the storage of slices, which would change the live game's
+	// settings even if the patch is rejected.
+	updated := g.Settings.Copy()
+	err = json.Unmarshal(patch, &updated)
+	if err != nil {
+		return nil, err
+	}
+
+	decks := g.Decks
+	if !sameIDs(updated.DeckIDs, g.Settings.DeckIDs) {
+		decks, err = h.loadDecks(updated.DeckIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = g.UpdateSettings(updated)
+	if err != nil {
+		return nil, err
+	}
+	g.Decks = decks
+
+	h.updateGame(gameID)
+	return &g.Settings, nil
+}
+
+// sameIDs reports whether two lists hold the same IDs in
+// the same order.
+func sameIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// subscribe adds a client to the group receiving updates
+// for a game.
+func (h *Hub) subscribe(gameID int, client *Client) {
+	if client == nil {
+		return
+	}
+
+	group, ok := h.subscribers[gameID]
+	if !ok {
+		group = make(map[*Client]bool)
+		h.subscribers[gameID] = group
+	}
+	group[client] = true
+}
+
+// unsubscribe removes a client from the group receiving
+// updates for a game.
+func (h *Hub) unsubscribe(gameID int, client *Client) {
+	delete(h.subscribers[gameID], client)
+}
+
+// updateGame saves a game after its state has changed
+// and broadcasts the new state to its subscribers.
+func (h *Hub) updateGame(gameID int) {
+	g, ok := h.Games[gameID]
+	if !ok {
+		return
+	}
+
+	h.saveGame(g)
+	h.broadcastGame(gameID)
+}
+
+// saveGame persists a game, if the Hub has a repository.
+func (h *Hub) saveGame(g *game.Game) {
+	if h.repo == nil {
+		return
+	}
+
+	if err := h.repo.Save(g); err != nil {
+		log.Printf("Could not save game %d: %v", g.ID, err)
+	}
+}
+
+// broadcastGame sends each subscriber of a game their own
+// view of its current state.
+func (h *Hub) broadcastGame(gameID int) {
+	g, ok := h.Games[gameID]
+	if !ok {
+		return
+	}
+
+	for client := range h.subscribers[gameID] {
+		client.Send(messages.OutgoingMessage{
+			Type: messages.GameState,
+			Data: g.ViewFor(h.clients[client]),
+		})
+	}
+}
+
+// broadcastResult announces the outcome of a game's latest
+// round to its subscribers.
+func (h *Hub) broadcastResult(gameID int) {
+	g, ok := h.Games[gameID]
+	if !ok {
+		return
+	}
+
+	result := g.LastResult()
+	if result == nil {
+		return
+	}
+
+	h.broadcast(gameID, messages.OutgoingMessage{
+		Type: messages.RoundEnded,
+		Data: result,
+	})
+}
+
+// broadcast sends the same message to every subscriber of
+// a game.
+func (h *Hub) broadcast(gameID int, msg messages.OutgoingMessage) {
+	for client := range h.subscribers[gameID] {
+		client.Send(msg)
+	}
+}
@@ -0,0 +1,356 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/api"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/messages"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/storage"
+)
+
+// newTestHub creates a Hub without storage holding a single
+// game in its lobby, owned by user 1.
+func newTestHub(t *testing.T) (*Hub, *game.Game) {
+	t.Helper()
+
+	owner := &game.Player{ID: 1, Username: "owner"}
+	g, err := game.Create(1, "Test game", "", owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = g.Join(&game.Player{ID: 2, Username: "player"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	g.Settings.DeckIDs = []int{1, 2}
+	g.Settings.TagFilter = game.TagFilter{Exclude: []string{"nsfw", "political"}}
+
+	h := &Hub{
+		Users: map[int]User{
+			1: {Username: "owner"},
+			2: {Username: "player"},
+		},
+		clients:     make(map[*Client]int),
+		Games:       map[int]*game.Game{g.ID: g},
+		subscribers: make(map[int]map[*Client]bool),
+		userCounter: 2,
+		gameCounter: 1,
+	}
+	return h, g
+}
+
+func TestHubUpdateSettings(t *testing.T) {
+	h, g := newTestHub(t)
+
+	settings, err := h.UpdateSettings(1, g.ID, json.RawMessage(`{"handSize":7,"tagFilter":{"exclude":["Kids"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if settings.HandSize != 7 || g.Settings.HandSize != 7 {
+		t.Errorf("hand size is %d, want 7", g.Settings.HandSize)
+	}
+	if want := []string{"kids"}; !reflect.DeepEqual(g.Settings.TagFilter.Exclude, want) {
+		t.Errorf("excluded tags are %q, want %q", g.Settings.TagFilter.Exclude, want)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(g.Settings.DeckIDs, want) {
+		t.Errorf("deck IDs are %v, want %v", g.Settings.DeckIDs, want)
+	}
+}
+
+func TestHubUpdateSettingsRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID int
+		gameID int
+		patch  string
+		err    error
+	}{
+		{"invalid setting", 1, 1, `{"handSize":1,"tagFilter":{"exclude":["kids"]},"deckIds":[9]}`, nil},
+		{"malformed", 1, 1, `{"tagFilter":{"exclude":["kids"]},"handSize":"big"}`, nil},
+		{"not owner", 2, 1, `{"tagFilter":{"exclude":["kids"]}}`, api.ErrNotGameOwner},
+		{"no such game", 1, 2, `{"tagFilter":{"exclude":["kids"]}}`, api.ErrGameNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, g := newTestHub(t)
+			before := g.Settings.Copy()
+
+			_, err := h.UpdateSettings(tt.userID, tt.gameID, json.RawMessage(tt.patch))
+			if err == nil {
+				t.Fatal("patch was accepted")
+			}
+			if tt.err != nil && err != tt.err {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+
+			if !reflect.DeepEqual(g.Settings, before) {
+				t.Errorf("live settings changed to %+v, want %+v", g.Settings, before)
+			}
+		})
+	}
+}
+
+// receivedMessage is an outgoing message read back from a
+// test client.
+type receivedMessage struct {
+	Type messages.OutgoingMessageType `json:"type"`
+	Data json.RawMessage              `json:"data"`
+}
+
+// newTestClient creates a client which keeps the messages
+// sent to it, rather than writing them to a connection.
+func newTestClient(h *Hub) *Client {
+	return &Client{hub: h, send: make(chan []byte, 64)}
+}
+
+// received reads back the messages sent to a test client
+// so far.
+func received(t *testing.T, c *Client) (msgs []receivedMessage) {
+	t.Helper()
+
+	for {
+		select {
+		case data := <-c.send:
+			msg := receivedMessage{}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatal(err)
+			}
+			msgs = append(msgs, msg)
+		default:
+			return
+		}
+	}
+}
+
+// receivedTypes lists the types of the messages sent to a
+// test client so far.
+func receivedTypes(t *testing.T, c *Client) (types []messages.OutgoingMessageType) {
+	t.Helper()
+
+	for _, msg := range received(t, c) {
+		types = append(types, msg.Type)
+	}
+	return
+}
+
+// testDeck creates a deck with enough cards for a game.
+func testDeck() *game.Deck {
+	deck := &game.Deck{ID: 1, Name: "Test deck"}
+	for i := 1; i <= 20; i++ {
+		deck.QuestionCards = append(deck.QuestionCards, game.QuestionCard{
+			ID: i, DeckID: 1, NumAnswers: 1, Text: fmt.Sprintf("Question %d is ____.", i),
+		})
+	}
+	for i := 1; i <= 200; i++ {
+		deck.AnswerCards = append(deck.AnswerCards, game.AnswerCard{
+			ID: i, DeckID: 1, Text: fmt.Sprintf("Answer %d.", i),
+		})
+	}
+	return deck
+}
+
+// startTestGame fills the test Hub's game with four players,
+// each subscribed through their own client, and starts it.
+func startTestGame(t *testing.T, h *Hub, g *game.Game, mode game.RuleMode) map[int]*Client {
+	t.Helper()
+
+	for id := 3; id <= 4; id++ {
+		name := fmt.Sprintf("player%d", id)
+		h.Users[id] = User{Username: name}
+		if err := g.Join(&game.Player{ID: id, Username: name}, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clients := map[int]*Client{}
+	for _, player := range g.Players {
+		clients[player.ID] = newTestClient(h)
+		user := h.Users[player.ID]
+		user.Client = clients[player.ID]
+		h.Users[player.ID] = user
+		h.clients[clients[player.ID]] = player.ID
+		h.subscribe(g.ID, clients[player.ID])
+	}
+
+	g.Decks = []*game.Deck{testDeck()}
+	g.Settings.Mode = mode
+	if err := g.SetSeed(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return clients
+}
+
+func TestLeaveSettlingVoteAnnouncesResult(t *testing.T) {
+	h, g := newTestHub(t)
+	clients := startTestGame(t, h, g, game.GodIsDead)
+
+	for _, player := range g.Players {
+		card := *player.Hand[0]
+		if err := g.SubmitCards(player.ID, []game.AnswerCard{card}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.Phase != game.Voting {
+		t.Fatalf("in %v, want voting", g.Phase)
+	}
+
+	// Everyone but player 4 votes, for player 1 or, being
+	// player 1, for player 2.
+	for _, voter := range []int{1, 2, 3} {
+		target := 1
+		if voter == 1 {
+			target = 2
+		}
+		if err := g.CastVote(voter, g.Round.Submission(target).ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	received(t, clients[1])
+
+	if err := h.LeaveGame(4, g.ID); err != nil {
+		t.Fatal(err)
+	}
+	if g.Phase != game.EndOfRound || g.Round.Winner == nil || g.Round.Winner.ID != 1 {
+		t.Fatalf("in %v with winner %v, want player 1 to have won the round", g.Phase, g.Round.Winner)
+	}
+
+	want := []messages.OutgoingMessageType{messages.GameState, messages.RoundEnded}
+	if got := receivedTypes(t, clients[1]); !reflect.DeepEqual(got, want) {
+		t.Errorf("got messages %v, want %v", got, want)
+	}
+	if got := receivedTypes(t, clients[4]); len(got) != 0 {
+		t.Errorf("player who left got messages %v", got)
+	}
+}
+
+func TestLeaveWithoutResult(t *testing.T) {
+	h, g := newTestHub(t)
+	clients := startTestGame(t, h, g, game.Standard)
+	received(t, clients[1])
+
+	leaving := g.Players[len(g.Players)-1].ID
+	if leaving == g.Round.Czar.ID || leaving == 1 {
+		t.Fatalf("player %d is needed by the test", leaving)
+	}
+	if err := h.LeaveGame(leaving, g.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []messages.OutgoingMessageType{messages.GameState}
+	if got := receivedTypes(t, clients[1]); !reflect.DeepEqual(got, want) {
+		t.Errorf("got messages %v, want %v", got, want)
+	}
+}
+
+func TestBroadcastReachesOnlySubscribers(t *testing.T) {
+	h, _ := newTestHub(t)
+	alice := connectTestClient(t, h, "alice")
+	bob := connectTestClient(t, h, "bobby")
+
+	h.dispatch(alice, []byte(`{"type":2,"data":{"name":"Alice's game"}}`))
+	h.dispatch(bob, []byte(`{"type":3,"data":{"gameId":1}}`))
+	received(t, alice)
+	received(t, bob)
+
+	h.broadcastGame(1)
+	if got := receivedTypes(t, bob); !reflect.DeepEqual(got, []messages.OutgoingMessageType{messages.GameState}) {
+		t.Errorf("subscriber got messages %v", got)
+	}
+	if got := receivedTypes(t, alice); len(got) != 0 {
+		t.Errorf("player of another game got messages %v", got)
+	}
+
+	h.broadcastGame(2)
+	if got := receivedTypes(t, bob); len(got) != 0 {
+		t.Errorf("player of another game got messages %v", got)
+	}
+
+	if err := h.LeaveGame(h.clients[bob], 1); err != nil {
+		t.Fatal(err)
+	}
+	h.broadcastGame(1)
+	if got := receivedTypes(t, bob); len(got) != 0 {
+		t.Errorf("player who left got messages %v", got)
+	}
+}
+
+func TestReconnectResubscribes(t *testing.T) {
+	h, _ := newTestHub(t)
+	old := connectTestClient(t, h, "alice")
+	h.dispatch(old, []byte(`{"type":3,"data":{"gameId":1}}`))
+	h.detach(old)
+	h.unsubscribe(1, old)
+
+	c := newTestClient(h)
+	c.username = "alice"
+	h.dispatch(c, []byte(`{"type":0}`))
+	want := []messages.OutgoingMessageType{messages.GameState, messages.Connected, messages.FullGamesList}
+	if got := receivedTypes(t, c); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got messages %v, want %v", got, want)
+	}
+
+	h.broadcastGame(1)
+	if got := receivedTypes(t, c); !reflect.DeepEqual(got, []messages.OutgoingMessageType{messages.GameState}) {
+		t.Errorf("reconnected client got messages %v", got)
+	}
+}
+
+func TestNewHubRestoresGames(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo, err := storage.NewGameRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, g := newTestHub(t)
+	g.ID = 5
+	empty, err := game.Create(7, "Empty game", "", &game.Player{ID: 9, Username: "gone"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = empty.Leave(9); err != nil {
+		t.Fatal(err)
+	}
+	for _, saved := range []*game.Game{g, empty} {
+		if err = repo.Save(saved); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h, err := NewHub(repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Games) != 1 || h.Games[5] == nil {
+		t.Fatalf("restored games %v, want game 5 alone", h.Games)
+	}
+	if h.gameCounter != 5 || h.userCounter != 2 {
+		t.Errorf("counters at game %d and user %d, want 5 and 2", h.gameCounter, h.userCounter)
+	}
+	if h.Users[1].Username != "owner" || h.Users[2].Username != "player" {
+		t.Errorf("restored users %+v", h.Users)
+	}
+
+	games, err := repo.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 {
+		t.Errorf("%d games left saved, want the empty game deleted", len(games))
+	}
+}
@@ -1,11 +1,18 @@
 package messages
 
+import (
+	"encoding/json"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+)
+
 // IncomingMessageType is the type of a message received
 // from a client.
 type IncomingMessageType int
 
 const (
-	// Connect is a connection attempt.
+	// Connect is a connection attempt as the user the
+	// websocket was opened for.
 	Connect IncomingMessageType = iota
 
 	// Disconnect is a disconnect attempt.
@@ -19,10 +26,95 @@ const (
 
 	// LeaveGame is an attempt to leave a joined game.
 	LeaveGame
+
+	// StartGame is an attempt by the owner to start a game.
+	StartGame
+
+	// SubmitCards is a player's answer to the current question.
+	SubmitCards
+
+	// SelectWinner is the Czar's choice of winning submission.
+	SelectWinner
+
+	// NextRound is an attempt to move on to the next round.
+	NextRound
+
+	// RebootUniverse is an attempt to trade a point for a
+	// fresh hand.
+	RebootUniverse
+
+	// Mulligan is an attempt to trade in a hand for free in
+	// the first round.
+	Mulligan
+
+	// CastVote is a player's vote for a winning submission,
+	// when there is no Czar.
+	CastVote
+
+	// UpdateSettings is an attempt by the owner to change the
+	// settings of a game in its lobby.
+	UpdateSettings
 )
 
 // IncomingMessage is an incoming message from a client.
+//
+// `Data` is left undecoded until the message type is known.
 type IncomingMessage struct {
 	Type IncomingMessageType `json:"type"`
-	Data interface{}         `json:"data,omitempty"`
+	Data json.RawMessage     `json:"data,omitempty"`
+}
+
+// CreateGameData is the payload of a `CreateGame` message.
+type CreateGameData struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+	DeckIDs  []int  `json:"deckIds"`
+}
+
+// JoinGameData is the payload of a `JoinGame` message.
+type JoinGameData struct {
+	GameID   int    `json:"gameId"`
+	Password string `json:"password"`
+}
+
+// LeaveGameData is the payload of a `LeaveGame` message.
+type LeaveGameData struct {
+	GameID int `json:"gameId"`
+}
+
+// GameActionData is the payload of messages which act on
+// a game without further detail, such as `StartGame`.
+type GameActionData struct {
+	GameID int `json:"gameId"`
+}
+
+// SubmitCardsData is the payload of a `SubmitCards` message.
+//
+// Blank cards carry the text written on them.
+type SubmitCardsData struct {
+	GameID int               `json:"gameId"`
+	Cards  []game.AnswerCard `json:"cards"`
+}
+
+// SelectWinnerData is the payload of a `SelectWinner` message.
+type SelectWinnerData struct {
+	GameID       int    `json:"gameId"`
+	SubmissionID string `json:"submissionId"`
+}
+
+// CastVoteData is the payload of a `CastVote` message.
+type CastVoteData struct {
+	GameID       int    `json:"gameId"`
+	SubmissionID string `json:"submissionId"`
+}
+
+// UpdateSettingsData is the payload of an `UpdateSettings`
+// message.
+//
+// `Settings` holds only the settings being changed, in the
+// form of `game.GameSettings`. This is the only way to change
+// settings such as the tag filter, house rules and rule mode.
+type UpdateSettingsData struct {
+	GameID   int             `json:"gameId"`
+	Settings json.RawMessage `json:"settings"`
 }
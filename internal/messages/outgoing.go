@@ -1,12 +1,52 @@
 package messages
 
-// OutgoingMessageType is the type of a message received
-// from a client.
+import (
+	"time"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+)
+
+// OutgoingMessageType is the type of a message sent
+// to a client.
 type OutgoingMessageType int
 
 const (
 	// FullGamesList will contain the full list of available games.
 	FullGamesList OutgoingMessageType = iota
+
+	// Error is a reply to a message that could not be handled.
+	Error
+
+	// Connected confirms a successful connection attempt.
+	Connected
+
+	// Disconnected confirms a successful disconnect attempt.
+	Disconnected
+
+	// GameCreated confirms that a game has been created.
+	GameCreated
+
+	// GameJoined confirms that a game has been joined.
+	GameJoined
+
+	// GameLeft confirms that a game has been left.
+	GameLeft
+
+	// GameState contains the recipient's view of a game
+	// after it has changed.
+	GameState
+
+	// RoundEnded announces the winner of a round, along with
+	// the completed sentence.
+	RoundEnded
+
+	// HandReplaced announces that a player has traded in
+	// their hand.
+	HandReplaced
+
+	// Countdown counts down the time left in a phase with a
+	// time limit. It is sent every second.
+	Countdown
 )
 
 // OutgoingMessage is an outgoing message from the server.
@@ -14,3 +54,70 @@ type OutgoingMessage struct {
 	Type OutgoingMessageType `json:"type"`
 	Data interface{}         `json:"data,omitempty"`
 }
+
+// ErrorCode categorises the reason for an `Error` reply.
+type ErrorCode string
+
+const (
+	// MalformedMessage is when a message could not be decoded.
+	MalformedMessage ErrorCode = "malformedMessage"
+
+	// UnknownMessage is when the message type isn't recognised.
+	UnknownMessage ErrorCode = "unknownMessage"
+
+	// NotConnected is when a message requires a connected user.
+	NotConnected ErrorCode = "notConnected"
+
+	// RequestFailed is when a valid request could not be carried out.
+	RequestFailed ErrorCode = "requestFailed"
+)
+
+// ErrorData is the payload of an `Error` reply.
+type ErrorData struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// NewError creates an `Error` reply with the given code.
+func NewError(code ErrorCode, message string) OutgoingMessage {
+	return OutgoingMessage{
+		Type: Error,
+		Data: ErrorData{Code: code, Message: message},
+	}
+}
+
+// ConnectedData is the payload of a `Connected` reply.
+type ConnectedData struct {
+	UserID   int    `json:"userId"`
+	Username string `json:"username"`
+}
+
+// GameInfo summarises a game for display in a games list.
+type GameInfo struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Owner       string `json:"owner"`
+	NumPlayers  int    `json:"numPlayers"`
+	HasPassword bool   `json:"hasPassword"`
+}
+
+// HandReplacedData is the payload of a `HandReplaced` message.
+type HandReplacedData struct {
+	GameID   int    `json:"gameId"`
+	PlayerID int    `json:"playerId"`
+	Username string `json:"username"`
+
+	// Reboot is set if the player paid a point to reboot
+	// the universe, rather than taking a mulligan.
+	Reboot bool `json:"reboot"`
+}
+
+// CountdownData is the payload of a `Countdown` message.
+type CountdownData struct {
+	GameID   int        `json:"gameId"`
+	Phase    game.Phase `json:"phase"`
+	Deadline time.Time  `json:"deadline"`
+
+	// Remaining is the number of whole seconds left.
+	Remaining int `json:"remaining"`
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// newUpgrader creates a websocket upgrader which only accepts
+// connections from the given origins or from the server's
+// own origin.
+func newUpgrader(allowedOrigins []string) *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		CheckOrigin:     checkOrigin(allowedOrigins),
+	}
+}
+
+// checkOrigin creates an origin check for websocket upgrades.
+//
+// Each allowed origin may be an exact origin such as
+// `https://example.com`, or a wildcard such as
+// `https://*.example.com` matching any subdomain. The scheme
+// may be left out to match any scheme.
+//
+// A bare `*` only applies to CORS. Websockets are
+// authenticated by the session cookie, so accepting them from
+// any site would let any site act as a logged in player.
+//
+// Requests without an `Origin` header don't come from a
+// browser and are always accepted.
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil || u.Host == "" {
+			log.Printf("Rejected websocket upgrade from malformed origin %q", origin)
+			return false
+		}
+
+		if strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+
+		for _, allowed := range allowedOrigins {
+			if matchOrigin(allowed, u) {
+				return true
+			}
+		}
+
+		log.Printf("Rejected websocket upgrade from origin %q", origin)
+		return false
+	}
+}
+
+// matchOrigin reports whether an origin matches an allowed
+// origin pattern.
+//
+// The pattern `*` matches nothing.
+func matchOrigin(pattern string, origin *url.URL) bool {
+	if pattern == "*" {
+		return false
+	}
+
+	if i := strings.Index(pattern, "://"); i >= 0 {
+		if !strings.EqualFold(pattern[:i], origin.Scheme) {
+			return false
+		}
+		pattern = pattern[i+3:]
+	}
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	host, port := splitHostPort(pattern)
+	if port != origin.Port() {
+		return false
+	}
+
+	originHost := strings.ToLower(origin.Hostname())
+	host = strings.ToLower(host)
+	if strings.HasPrefix(host, "*.") {
+		return strings.HasSuffix(originHost, host[1:])
+	}
+
+	return originHost == host
+}
+
+// splitHostPort splits a host pattern into its host and
+// optional port.
+func splitHostPort(hostport string) (host, port string) {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return hostport, ""
+	}
+	return host, port
+}
@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestMatchOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		origin  string
+		want    bool
+	}{
+		{"exact", "https://example.com", "https://example.com", true},
+		{"exact with trailing slash", "https://example.com/", "https://example.com", true},
+		{"exact other host", "https://example.com", "https://evil.com", false},
+		{"exact case insensitive", "HTTPS://Example.COM", "https://example.com", true},
+		{"scheme mismatch", "https://example.com", "http://example.com", false},
+		{"wildcard subdomain", "https://*.example.com", "https://play.example.com", true},
+		{"wildcard nested subdomain", "https://*.example.com", "https://a.b.example.com", true},
+		{"wildcard excludes apex", "https://*.example.com", "https://example.com", false},
+		{"wildcard excludes lookalike", "https://*.example.com", "https://evilexample.com", false},
+		{"scheme-less http", "example.com", "http://example.com", true},
+		{"scheme-less https", "example.com", "https://example.com", true},
+		{"scheme-less wildcard", "*.example.com", "https://play.example.com", true},
+		{"port match", "https://example.com:8443", "https://example.com:8443", true},
+		{"port mismatch", "https://example.com:8443", "https://example.com:9000", false},
+		{"port missing from origin", "https://example.com:8443", "https://example.com", false},
+		{"port missing from pattern", "https://example.com", "https://example.com:8443", false},
+		{"star matches nothing", "*", "https://example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origin, err := url.Parse(tt.origin)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := matchOrigin(tt.pattern, origin); got != tt.want {
+				t.Errorf("matchOrigin(%q, %q) = %v, want %v", tt.pattern, tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		host    string
+		origin  string
+		want    bool
+	}{
+		{"no origin header", nil, "tah.example.com", "", true},
+		{"same origin", nil, "tah.example.com", "https://tah.example.com", true},
+		{"same origin with port", nil, "localhost:8000", "http://localhost:8000", true},
+		{"same host other port", nil, "localhost:8000", "http://localhost:3000", false},
+		{"cross origin by default", nil, "tah.example.com", "https://evil.com", false},
+		{"cross origin with star", []string{"*"}, "tah.example.com", "https://evil.com", false},
+		{"allowed exact", []string{"https://client.example.com"}, "tah.example.com", "https://client.example.com", true},
+		{"allowed wildcard", []string{"https://*.example.com"}, "tah.example.net", "https://client.example.com", true},
+		{"allowed scheme-less", []string{"client.example.com"}, "tah.example.com", "http://client.example.com", true},
+		{"allowed port mismatch", []string{"http://localhost:3000"}, "localhost:8000", "http://localhost:3001", false},
+		{"second pattern", []string{"https://a.com", "https://b.com"}, "tah.example.com", "https://b.com", true},
+		{"malformed origin", []string{"*"}, "tah.example.com", "::not a url", false},
+		{"null origin", []string{"*"}, "tah.example.com", "null", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "http://"+tt.host+"/ws", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+
+			if got := checkOrigin(tt.allowed)(r); got != tt.want {
+				t.Errorf("checkOrigin(%q) for origin %q on host %q = %v, want %v", tt.allowed, tt.origin, tt.host, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"encoding/json"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/api"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+)
+
+// ListGames summarises all listed games for the API.
+func (h *Hub) ListGames() (infos []api.RoomInfo) {
+	h.do(func() {
+		infos = make([]api.RoomInfo, 0, len(h.Games))
+		for _, g := range h.Games {
+			if g.Settings.Visibility == game.Unlisted {
+				continue
+			}
+			infos = append(infos, api.RoomInfo{ID: g.ID, Name: g.Name})
+		}
+	})
+	return
+}
+
+// CreateGame creates a game on behalf of an API user.
+//
+// The user is added to the Hub if they have yet to connect.
+func (h *Hub) CreateGame(username, name, password string, deckIDs []int) (info *api.RoomInfo, err error) {
+	h.do(func() {
+		userID := h.userID(username)
+		if userID == 0 {
+			userID, err = h.AddUser(username, nil)
+			if err != nil {
+				return
+			}
+		}
+
+		var id int
+		id, err = h.AddGame(userID, name, password, deckIDs)
+		if err != nil {
+			return
+		}
+
+		info = &api.RoomInfo{ID: id, Name: name}
+		h.broadcastGame(id)
+	})
+	return
+}
+
+// PatchSettings changes the settings of a game on behalf of
+// an API user who owns it.
+func (h *Hub) PatchSettings(username string, gameID int, patch json.RawMessage) (settings *game.GameSettings, err error) {
+	h.do(func() {
+		userID := h.userID(username)
+		if userID == 0 {
+			err = api.ErrNotGameOwner
+			return
+		}
+
+		settings, err = h.UpdateSettings(userID, gameID, patch)
+		if settings != nil {
+			copied := *settings
+			settings = &copied
+		}
+	})
+	return
+}
@@ -11,19 +11,12 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/rjacobs31/trees-against-humanity-server/internal/api"
+	"github.com/rjacobs31/trees-against-humanity-server/internal/storage"
 	"github.com/yosssi/boltstore/store"
 )
 
 const templateDir string = "./web/template/"
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  4096,
-	WriteBufferSize: 4096,
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
-}
-
 // ServeConfig specifies options for configuring
 // the Serve command.
 type ServeConfig struct {
@@ -46,7 +39,23 @@ func Serve(config ServeConfig) {
 		log.Fatal("Open session store: ", err)
 	}
 
-	r, err := mainRouter(str)
+	repo, err := storage.NewGameRepository(db)
+	if err != nil {
+		log.Fatal("Open game repository: ", err)
+	}
+
+	decks, err := storage.NewDeckRepository(db)
+	if err != nil {
+		log.Fatal("Open deck repository: ", err)
+	}
+
+	hub, err := NewHub(repo, decks)
+	if err != nil {
+		log.Fatal("Restore games: ", err)
+	}
+	go hub.Run()
+
+	r, err := mainRouter(str, hub, decks, config)
 	if err != nil {
 		log.Fatal("Open router: ", err)
 	}
@@ -67,15 +76,13 @@ func Serve(config ServeConfig) {
 	}
 }
 
-func mainRouter(str *store.Store) (r *mux.Router, err error) {
+func mainRouter(str *store.Store, hub *Hub, decks *storage.DeckRepository, config ServeConfig) (r *mux.Router, err error) {
 	r = mux.NewRouter()
 
 	apiRouter := r.PathPrefix("/api").Subrouter()
-	api.Setup(apiRouter, str)
+	api.Setup(apiRouter, str, hub, decks)
 
-	hub := &Hub{}
-	go hub.Run()
-	r.HandleFunc("/ws", handleWebsocket(hub))
+	r.HandleFunc("/ws", handleWebsocket(hub, str, newUpgrader(config.AllowedOrigins)))
 
 	r.Handle("/static", http.StripPrefix("/static/", http.FileServer(http.Dir("./web/static/"))))
 
@@ -120,8 +127,8 @@ func loginHandler(str *store.Store) http.HandlerFunc {
 
 		if r.Method == "POST" {
 			enteredName := r.FormValue("username")
-			if enteredName == "" {
-				session.AddFlash("Invalid name")
+			if err := api.ValidateUsername(enteredName); err != nil {
+				session.AddFlash(err.Error())
 				http.Redirect(w, r, "/login", http.StatusTemporaryRedirect)
 				return
 			}
@@ -159,9 +166,9 @@ func logoutHandler(str *store.Store) http.HandlerFunc {
 	}
 }
 
-func handleWebsocket(hub *Hub) http.HandlerFunc {
+func handleWebsocket(hub *Hub, str *store.Store, upgrader *websocket.Upgrader) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ServeWs(hub, w, r)
+		ServeWs(hub, upgrader, str, w, r)
 	}
 }
 
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+)
+
+var decksBucket = []byte("decks")
+
+// ErrDeckNotFound is returned when a deck does not exist.
+var ErrDeckNotFound = errors.New("deck not found")
+
+// DeckRepository stores the library of decks in a BoltDB
+// database.
+type DeckRepository struct {
+	db *bolt.DB
+}
+
+// NewDeckRepository creates a repository backed by the given
+// database, creating its bucket if necessary.
+func NewDeckRepository(db *bolt.DB) (repo *DeckRepository, err error) {
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(decksBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeckRepository{db: db}, nil
+}
+
+// List retrieves every deck in the library.
+func (r *DeckRepository) List() (decks []game.Deck, err error) {
+	decks = []game.Deck{}
+	err = r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(decksBucket).ForEach(func(k, v []byte) error {
+			deck := game.Deck{}
+			if err := json.Unmarshal(v, &deck); err != nil {
+				return err
+			}
+			decks = append(decks, deck)
+			return nil
+		})
+	})
+	return
+}
+
+// Get retrieves a single deck.
+func (r *DeckRepository) Get(id int) (deck *game.Deck, err error) {
+	err = r.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(decksBucket).Get(itob(id))
+		if data == nil {
+			return ErrDeckNotFound
+		}
+		deck = &game.Deck{}
+		return json.Unmarshal(data, deck)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deck, nil
+}
+
+// Create adds a deck to the library, giving it a new ID.
+func (r *DeckRepository) Create(deck *game.Deck) (err error) {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(decksBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		deck.ID = int(seq)
+		deck.NumberCards()
+		return put(b, deck)
+	})
+}
+
+// Update replaces a deck already in the library.
+//
+// New cards, and cards repeating another's ID, are given IDs
+// the deck has never used before.
+func (r *DeckRepository) Update(deck *game.Deck) (err error) {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(decksBucket)
+		data := b.Get(itob(deck.ID))
+		if data == nil {
+			return ErrDeckNotFound
+		}
+
+		// Keep counting from the stored deck, so that IDs of
+		// removed cards aren't reused.
+		stored := game.Deck{}
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return err
+		}
+		if stored.LastQuestionID > deck.LastQuestionID {
+			deck.LastQuestionID = stored.LastQuestionID
+		}
+		if stored.LastAnswerID > deck.LastAnswerID {
+			deck.LastAnswerID = stored.LastAnswerID
+		}
+
+		deck.NumberCards()
+		return put(b, deck)
+	})
+}
+
+// Delete removes a deck from the library.
+func (r *DeckRepository) Delete(id int) (err error) {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(decksBucket)
+		if b.Get(itob(id)) == nil {
+			return ErrDeckNotFound
+		}
+		return b.Delete(itob(id))
+	})
+}
+
+func put(b *bolt.Bucket, deck *game.Deck) error {
+	data, err := json.Marshal(deck)
+	if err != nil {
+		return err
+	}
+	return b.Put(itob(deck.ID), data)
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+)
+
+// openTestDB opens a database which is removed once the test
+// finishes.
+func openTestDB(t *testing.T) *bolt.DB {
+	t.Helper()
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newTestDeckRepository(t *testing.T) *DeckRepository {
+	t.Helper()
+
+	repo, err := NewDeckRepository(openTestDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func answerIDs(deck *game.Deck) []int {
+	ids := make([]int, 0, len(deck.AnswerCards))
+	for _, card := range deck.AnswerCards {
+		ids = append(ids, card.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDeckCreateNumbersCards(t *testing.T) {
+	repo := newTestDeckRepository(t)
+
+	deck := &game.Deck{
+		Name: "Base",
+		QuestionCards: []game.QuestionCard{
+			{ID: 4, Text: "Why? ____"},
+			{ID: 4, Text: "What? ____"},
+		},
+		AnswerCards: []game.AnswerCard{
+			{ID: 2, Text: "Bees?"},
+			{Text: "Oprah."},
+			{ID: 2, Text: "Vigorous jazz hands."},
+			{ID: -1, Text: "A windmill."},
+		},
+	}
+	if err := repo.Create(deck); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := repo.Get(deck.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := answerIDs(stored), []int{2, 3, 4, 5}; !equalIDs(got, want) {
+		t.Errorf("answer IDs are %v, want %v", got, want)
+	}
+	if a, b := stored.QuestionCards[0].ID, stored.QuestionCards[1].ID; a != 4 || b != 5 {
+		t.Errorf("question IDs are %d and %d, want 4 and 5", a, b)
+	}
+	for _, card := range stored.AnswerCards {
+		if card.DeckID != deck.ID {
+			t.Errorf("answer %d belongs to deck %d, want %d", card.ID, card.DeckID, deck.ID)
+		}
+	}
+}
+
+func TestDeckUpdateDoesNotReuseIDs(t *testing.T) {
+	repo := newTestDeckRepository(t)
+
+	deck := &game.Deck{
+		Name:        "Base",
+		AnswerCards: []game.AnswerCard{{Text: "Bees?"}, {Text: "Oprah."}},
+	}
+	if err := repo.Create(deck); err != nil {
+		t.Fatal(err)
+	}
+
+	// Clients don't send back the highest ID used, so remove
+	// the card holding it and forget the count.
+	deck.AnswerCards = deck.AnswerCards[:1]
+	deck.LastAnswerID = 0
+	if err := repo.Update(deck); err != nil {
+		t.Fatal(err)
+	}
+
+	deck.AnswerCards = append(deck.AnswerCards, game.AnswerCard{Text: "Vigorous jazz hands."})
+	if err := repo.Update(deck); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := repo.Get(deck.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := answerIDs(stored), []int{1, 3}; !equalIDs(got, want) {
+		t.Errorf("answer IDs are %v, want %v", got, want)
+	}
+}
+
+func TestDeckUpdateMissing(t *testing.T) {
+	repo := newTestDeckRepository(t)
+
+	err := repo.Update(&game.Deck{ID: 7, Name: "Missing"})
+	if err != ErrDeckNotFound {
+		t.Errorf("got error %v, want %v", err, ErrDeckNotFound)
+	}
+}
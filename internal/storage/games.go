@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"encoding/binary"
+	"log"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+)
+
+var gamesBucket = []byte("games")
+
+// GameRepository stores snapshots of games in a BoltDB
+// database.
+type GameRepository struct {
+	db *bolt.DB
+}
+
+// NewGameRepository creates a repository backed by the given
+// database, creating its bucket if necessary.
+func NewGameRepository(db *bolt.DB) (repo *GameRepository, err error) {
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(gamesBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GameRepository{db: db}, nil
+}
+
+// Save stores the current state of a game, replacing any
+// previously saved state.
+func (r *GameRepository) Save(g *game.Game) (err error) {
+	data, err := game.MarshalSnapshot(g)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(gamesBucket).Put(itob(g.ID), data)
+	})
+}
+
+// Delete removes a saved game.
+func (r *GameRepository) Delete(id int) (err error) {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(gamesBucket).Delete(itob(id))
+	})
+}
+
+// LoadAll restores every saved game.
+//
+// Games which can't be restored are logged and skipped.
+func (r *GameRepository) LoadAll() (games []*game.Game, err error) {
+	err = r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(gamesBucket).ForEach(func(k, v []byte) error {
+			g, err := game.UnmarshalSnapshot(v)
+			if err != nil {
+				log.Printf("Could not restore game %d: %v", btoi(k), err)
+				return nil
+			}
+			games = append(games, g)
+			return nil
+		})
+	})
+	return
+}
+
+// itob encodes an ID as a big-endian key, so that keys
+// sort in ID order.
+func itob(id int) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(id))
+	return b
+}
+
+// btoi decodes a key created by itob.
+func btoi(b []byte) int {
+	return int(binary.BigEndian.Uint64(b))
+}
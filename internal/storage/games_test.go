@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/rjacobs31/trees-against-humanity-server/internal/game"
+)
+
+func newTestGameRepository(t *testing.T, db *bolt.DB) *GameRepository {
+	t.Helper()
+
+	repo, err := NewGameRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// newStoredGame creates a game in its lobby with two players.
+func newStoredGame(t *testing.T, id int, name string) *game.Game {
+	t.Helper()
+
+	g, err := game.Create(id, name, "", &game.Player{ID: 1, Username: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = g.Join(&game.Player{ID: 2, Username: "bobby"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// snapshotJSON marshals a game's snapshot for comparison.
+func snapshotJSON(t *testing.T, g *game.Game) string {
+	t.Helper()
+
+	data, err := game.MarshalSnapshot(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGamesSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	games := []*game.Game{newStoredGame(t, 2, "Second"), newStoredGame(t, 10, "Tenth")}
+	repo := newTestGameRepository(t, db)
+	for _, g := range games {
+		if err := repo.Save(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	loaded, err := newTestGameRepository(t, db).LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != len(games) {
+		t.Fatalf("loaded %d games, want %d", len(loaded), len(games))
+	}
+	for i, g := range games {
+		if got, want := snapshotJSON(t, loaded[i]), snapshotJSON(t, g); got != want {
+			t.Errorf("game %d loaded as\n%s\nwant\n%s", g.ID, got, want)
+		}
+	}
+}
+
+func TestGameSaveReplaces(t *testing.T) {
+	repo := newTestGameRepository(t, openTestDB(t))
+	g := newStoredGame(t, 1, "Game")
+	if err := repo.Save(g); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Leave(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save(g); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := repo.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || len(loaded[0].Players) != 1 {
+		t.Errorf("loaded %d games, want the one game as last saved", len(loaded))
+	}
+}
+
+func TestGameDelete(t *testing.T) {
+	repo := newTestGameRepository(t, openTestDB(t))
+	for id := 1; id <= 2; id++ {
+		if err := repo.Save(newStoredGame(t, id, "Game")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(9); err != nil {
+		t.Errorf("deleting a missing game failed: %v", err)
+	}
+
+	loaded, err := repo.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0].ID != 2 {
+		t.Errorf("loaded %d games, want game 2 alone", len(loaded))
+	}
+}
+
+func TestLoadAllSkipsUnreadableGames(t *testing.T) {
+	db := openTestDB(t)
+	repo := newTestGameRepository(t, db)
+	if err := repo.Save(newStoredGame(t, 2, "Game")); err != nil {
+		t.Fatal(err)
+	}
+	err := db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(gamesBucket).Put(itob(1), []byte(`{"version":`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := repo.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0].ID != 2 {
+		t.Errorf("loaded %d games, want game 2 alone", len(loaded))
+	}
+}